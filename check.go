@@ -0,0 +1,162 @@
+package mc_upload_api
+
+import (
+	"context"
+	"errors"
+	"github.com/mrmelon54/mc-upload-api/database"
+	"github.com/mrmelon54/mc-upload-api/storage"
+	"time"
+)
+
+// OrphanGracePeriod keeps recently stored files out of the orphans, uploads are
+// stored before their build is committed
+const OrphanGracePeriod = time.Hour
+
+// StorageReport lists the differences between the database and the storage
+type StorageReport struct {
+	// MissingBuilds have no stored jar
+	MissingBuilds []database.ListStoredBuildsRow
+
+	// MissingFiles are additional files without a stored file
+	MissingFiles []database.BuildFile
+
+	// Orphans are stored files without a build older than OrphanGracePeriod,
+	// only found if the storage can be listed
+	Orphans []string
+}
+
+func (s StorageReport) Ok() bool {
+	return len(s.MissingBuilds) == 0 && len(s.MissingFiles) == 0 && len(s.Orphans) == 0
+}
+
+// CheckStorage compares the builds with the stored files and fills in missing
+// build sizes, when repair is true builds without a jar are deleted with their
+// files, platforms and jobs so the jar can be uploaded again, releases left
+// without builds are deleted and orphaned files are removed from the storage
+// unless it is shared
+//
+// This must run before the server and publish queue are started.
+func CheckStorage(ctx context.Context, db *database.Queries, store storage.Storage, repair bool) (StorageReport, error) {
+	var report StorageReport
+	builds, err := db.ListStoredBuilds(ctx)
+	if err != nil {
+		return report, err
+	}
+	files, err := db.ListAllBuildFiles(ctx)
+	if err != nil {
+		return report, err
+	}
+
+	stored := make(map[string]bool)
+	sizes := make(map[string]int64)
+	exists := func(sha512 string) (bool, error) {
+		if v, ok := stored[sha512]; ok {
+			return v, nil
+		}
+		info, err := store.Stat(ctx, sha512)
+		if errors.Is(err, storage.ErrNotExist) {
+			stored[sha512] = false
+			return false, nil
+		} else if err != nil {
+			return false, err
+		}
+		stored[sha512] = true
+		sizes[sha512] = info.Size
+		return true, nil
+	}
+
+	missingBuild := make(map[int64]bool)
+	for _, i := range builds {
+		ok, err := exists(i.Sha512)
+		if err != nil {
+			return report, err
+		}
+		if !ok {
+			missingBuild[i.ID] = true
+			report.MissingBuilds = append(report.MissingBuilds, i)
+			continue
+		}
+		// builds from before the size was stored
+		if i.Size == 0 {
+			err := db.SetBuildSize(ctx, database.SetBuildSizeParams{Size: sizes[i.Sha512], ID: i.ID})
+			if err != nil {
+				return report, err
+			}
+		}
+	}
+	for _, i := range files {
+		ok, err := exists(i.Sha512)
+		if err != nil {
+			return report, err
+		}
+		if !ok {
+			report.MissingFiles = append(report.MissingFiles, i)
+		}
+	}
+
+	if lister, ok := store.(storage.Lister); ok {
+		hashes, err := lister.List(ctx)
+		if err != nil {
+			return report, err
+		}
+		for _, i := range hashes {
+			// every referenced hash was checked above
+			if _, ok := stored[i]; ok {
+				continue
+			}
+			info, err := store.Stat(ctx, i)
+			if errors.Is(err, storage.ErrNotExist) {
+				continue
+			} else if err != nil {
+				return report, err
+			}
+			if time.Since(info.ModTime) >= OrphanGracePeriod {
+				report.Orphans = append(report.Orphans, i)
+			}
+		}
+	}
+
+	if !repair {
+		return report, nil
+	}
+	for _, i := range report.MissingBuilds {
+		err := db.InTx(ctx, func(q *database.Queries) error {
+			if err := q.DeleteJobsForBuild(ctx, i.ID); err != nil {
+				return err
+			}
+			if err := q.DeletePlatformsForBuild(ctx, i.ID); err != nil {
+				return err
+			}
+			if err := q.DeleteFilesForBuild(ctx, i.ID); err != nil {
+				return err
+			}
+			return q.DeleteBuild(ctx, i.ID)
+		})
+		if err != nil {
+			return report, err
+		}
+	}
+	if len(report.MissingBuilds) > 0 {
+		if err := db.DeleteEmptyReleases(ctx); err != nil {
+			return report, err
+		}
+	}
+	for _, i := range report.MissingFiles {
+		if missingBuild[i.BuildID] {
+			continue
+		}
+		err := db.DeleteBuildFile(ctx, database.DeleteBuildFileParams{BuildID: i.BuildID, Kind: i.Kind})
+		if err != nil {
+			return report, err
+		}
+	}
+	if storage.Shared(store) {
+		return report, nil
+	}
+	for _, i := range report.Orphans {
+		if err := store.Delete(ctx, i); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
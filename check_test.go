@@ -0,0 +1,120 @@
+package mc_upload_api
+
+import (
+	"context"
+	"database/sql"
+	"github.com/mrmelon54/mc-upload-api/database"
+	"github.com/mrmelon54/mc-upload-api/storage"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sharedStorage is local storage which is treated as shared
+type sharedStorage struct {
+	*storage.Local
+}
+
+func TestCheckStorage(t *testing.T) {
+	db := testDB(t)
+	dir := t.TempDir()
+	store, err := storage.NewLocal(dir)
+	assert.NoError(t, err)
+	ctx := context.Background()
+	hash := func(c string) string { return strings.Repeat(c, 128) }
+	put := func(sha512, data string) {
+		assert.NoError(t, store.Put(ctx, sha512, strings.NewReader(data), int64(len(data))))
+	}
+
+	// build 1 is stored but its sources file is missing
+	build1, err := db.CreateBuild(ctx, database.CreateBuildParams{Project: "clock", Sha512: hash("1")})
+	assert.NoError(t, err)
+	put(hash("1"), "hello")
+	assert.NoError(t, db.CreateBuildFile(ctx, database.CreateBuildFileParams{BuildID: build1, Kind: "sources", Sha512: hash("2")}))
+
+	// build 2 is in a release and its jar is missing
+	releaseId, err := db.CreateRelease(ctx, database.CreateReleaseParams{Project: "clock"})
+	assert.NoError(t, err)
+	build2, err := db.CreateBuild(ctx, database.CreateBuildParams{Project: "clock", Sha512: hash("3"), ReleaseID: releaseId})
+	assert.NoError(t, err)
+	assert.NoError(t, db.CreateBuildFile(ctx, database.CreateBuildFileParams{BuildID: build2, Kind: "javadoc", Sha512: hash("4")}))
+	assert.NoError(t, db.SetBuildPlatform(ctx, database.SetBuildPlatformParams{BuildID: build2, Platform: "modrinth"}))
+	jobId, err := db.CreateJob(ctx, database.CreateJobParams{BuildID: build2})
+	assert.NoError(t, err)
+
+	// a file without a build and a recent upload which is not committed yet
+	put(hash("5"), "orphan")
+	old := time.Now().Add(-OrphanGracePeriod)
+	assert.NoError(t, os.Chtimes(filepath.Join(dir, hash("5")+".jar"), old, old))
+	put(hash("6"), "uploading")
+
+	report, err := CheckStorage(ctx, db, store, false)
+	assert.NoError(t, err)
+	assert.False(t, report.Ok())
+	assert.Equal(t, []database.ListStoredBuildsRow{{ID: build2, Project: "clock", Sha512: hash("3")}}, report.MissingBuilds)
+	assert.Len(t, report.MissingFiles, 2)
+	assert.Equal(t, []string{hash("5")}, report.Orphans)
+
+	// the size of build 1 is filled in without repairing
+	build, err := db.GetBuildByID(ctx, build1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), build.Size)
+	_, err = db.GetBuildByID(ctx, build2)
+	assert.NoError(t, err)
+
+	report, err = CheckStorage(ctx, db, store, true)
+	assert.NoError(t, err)
+	assert.Len(t, report.MissingBuilds, 1)
+
+	_, err = db.GetBuildByID(ctx, build2)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = db.GetJob(ctx, jobId)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = db.GetRelease(ctx, releaseId)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	platforms, err := db.ListPlatformsForBuild(ctx, build2)
+	assert.NoError(t, err)
+	assert.Empty(t, platforms)
+	files, err := db.ListAllBuildFiles(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, files)
+	_, err = store.Stat(ctx, hash("5"))
+	assert.ErrorIs(t, err, storage.ErrNotExist)
+	obj, err := store.Get(ctx, hash("1"))
+	assert.NoError(t, err)
+	b, err := io.ReadAll(obj)
+	assert.NoError(t, err)
+	assert.NoError(t, obj.Close())
+	assert.Equal(t, "hello", string(b))
+
+	_, err = store.Stat(ctx, hash("6"))
+	assert.NoError(t, err)
+
+	report, err = CheckStorage(ctx, db, store, false)
+	assert.NoError(t, err)
+	assert.True(t, report.Ok())
+}
+
+func TestCheckStorage_Shared(t *testing.T) {
+	db := testDB(t)
+	dir := t.TempDir()
+	local, err := storage.NewLocal(dir)
+	assert.NoError(t, err)
+	store := sharedStorage{local}
+	ctx := context.Background()
+	sha512 := strings.Repeat("5", 128)
+	assert.NoError(t, store.Put(ctx, sha512, strings.NewReader("orphan"), 6))
+	old := time.Now().Add(-OrphanGracePeriod)
+	assert.NoError(t, os.Chtimes(filepath.Join(dir, sha512+".jar"), old, old))
+
+	// orphans may belong to builds of other instances so they are only reported
+	report, err := CheckStorage(ctx, db, store, true)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{sha512}, report.Orphans)
+	_, err = store.Stat(ctx, sha512)
+	assert.NoError(t, err)
+}
@@ -1,12 +1,16 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"flag"
 	exitReload "github.com/mrmelon54/exit-reload"
 	mcuploadapi "github.com/mrmelon54/mc-upload-api"
 	"github.com/mrmelon54/mc-upload-api/cmd/mc-upload-api/routes"
+	"github.com/mrmelon54/mc-upload-api/database"
+	"github.com/mrmelon54/mc-upload-api/publisher"
 	resolveversions "github.com/mrmelon54/mc-upload-api/resolve-versions"
+	"github.com/mrmelon54/mc-upload-api/storage"
 	"github.com/mrmelon54/mc-upload-api/uploader"
 	"gopkg.in/yaml.v3"
 	"log"
@@ -19,8 +23,10 @@ import (
 
 func main() {
 	var configYmlPath string
+	var repair bool
 
 	flag.StringVar(&configYmlPath, "conf", "", "Path to the config file")
+	flag.BoolVar(&repair, "repair", false, "Delete builds with missing jars and stored files without a build in local storage on startup")
 	flag.Parse()
 
 	log.Printf("[Main] Starting up MC Upload API\n")
@@ -38,34 +44,32 @@ func main() {
 		log.Fatalln("Failed to load projects:", err)
 	}
 
-	buildDir := filepath.Join(wd, "builds")
-	stat, err := os.Stat(buildDir)
-	switch {
-	case os.IsNotExist(err):
-		err := os.Mkdir(buildDir, 0775)
-		if err != nil {
-			log.Fatalln("buildDir could not be created")
-		}
-	case err != nil:
-		log.Fatalln("buildDir error:", err)
-	default:
-		if !stat.IsDir() {
-			log.Fatalln("buildDir is not a directory")
-		}
+	store, err := storage.New(configYml.Load().Storage, wd, http.DefaultClient)
+	if err != nil {
+		log.Fatalln("Failed to load storage:", err)
 	}
 
 	db, err := mcuploadapi.InitDB(filepath.Join(wd, "builds.sqlite3.db"))
 	if err != nil {
 		log.Fatalln("[DatabaseError] ", err)
 	}
+	checkStorage(db, store, repair)
 
-	mrUpld := uploader.NewModrinthUploader(configYml.Load().Modrinth, http.DefaultClient)
-	cfUpld := uploader.NewCurseforgeUploader(configYml.Load().Curseforge, http.DefaultClient)
+	uploaders, err := uploader.NewRegistry(configYml.Load().Platforms, http.DefaultClient)
+	if err != nil {
+		log.Fatalln("Failed to load platforms:", err)
+	}
 	mcVersions := resolveversions.NewMcVersionCache(http.DefaultClient)
 
+	queueCtx, queueCancel := context.WithCancel(context.Background())
+	queue := publisher.NewQueue(db, publisher.New(db, store, uploaders), projectsYml)
+	if err := queue.Start(queueCtx, configYml.Load().Workers()); err != nil {
+		log.Fatalln("Failed to start publish queue:", err)
+	}
+
 	srv := &http.Server{
 		Addr:              configYml.Load().Listen,
-		Handler:           routes.Router(db, projectsYml, buildDir, mrUpld, cfUpld, mcVersions),
+		Handler:           routes.Router(db, configYml, projectsYml, store, queue, uploaders, mcVersions),
 		ReadTimeout:       time.Minute,
 		ReadHeaderTimeout: time.Minute,
 		WriteTimeout:      time.Minute,
@@ -93,9 +97,36 @@ func main() {
 		if err != nil {
 			log.Println(err)
 		}
+		queueCancel()
+		queue.Wait()
 	})
 }
 
+func checkStorage(db *database.Queries, store storage.Storage, repair bool) {
+	report, err := mcuploadapi.CheckStorage(context.Background(), db, store, repair)
+	if err != nil {
+		log.Fatalln("Failed to check storage:", err)
+	}
+	for _, i := range report.MissingBuilds {
+		log.Printf("[Storage] Build %d of %s is missing jar %s\n", i.ID, i.Project, i.Sha512)
+	}
+	for _, i := range report.MissingFiles {
+		log.Printf("[Storage] Build %d is missing %s file %s\n", i.BuildID, i.Kind, i.Sha512)
+	}
+	for _, i := range report.Orphans {
+		log.Printf("[Storage] Stored file %s has no build\n", i)
+	}
+	switch {
+	case report.Ok():
+	case repair && len(report.Orphans) > 0 && storage.Shared(store):
+		log.Printf("[Storage] Repaired storage, files without a build are kept in shared storage\n")
+	case repair:
+		log.Printf("[Storage] Repaired storage\n")
+	default:
+		log.Printf("[Storage] Run with -repair to remove the inconsistent builds and files\n")
+	}
+}
+
 func loadConfig[T any](ptr *atomic.Pointer[T], p string) error {
 	var c T
 	file, err := os.Open(p)
@@ -0,0 +1,169 @@
+package routes
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/julienschmidt/httprouter"
+	"github.com/mrmelon54/mc-upload-api"
+	"github.com/mrmelon54/mc-upload-api/database"
+	"github.com/mrmelon54/mc-upload-api/storage"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+)
+
+// modDownloadGet serves a stored jar or additional file of the project, the
+// sha512 `latest` serves the newest listed build matching the loader,
+// game_version and channel query
+func (r routeCtx) modDownloadGet(rw http.ResponseWriter, req *http.Request, params httprouter.Params) {
+	slug := params.ByName("slug")
+	project, ok := (*r.projectsYml.Load())[slug]
+	if !ok {
+		http.Error(rw, "404 Not Found", http.StatusNotFound)
+		return
+	}
+
+	// httprouter does not allow a static segment next to the sha512 parameter
+	sha512 := params.ByName("sha512")
+	latest := sha512 == "latest"
+	var build database.Build
+	if latest {
+		rows, err := r.db.ListBuilds(req.Context(), slug)
+		if err != nil {
+			log.Println("Database Error:", err)
+			http.Error(rw, "Database Error", http.StatusInternalServerError)
+			return
+		}
+		row, ok := latestBuild(listedBuilds(project, rows, time.Now()), req.URL.Query())
+		if !ok {
+			http.Error(rw, "404 Not Found", http.StatusNotFound)
+			return
+		}
+		build = listedBuild(slug, row)
+		sha512 = build.Sha512
+	} else {
+		var err error
+		build, err = r.db.GetBuildByFileHash(req.Context(), sha512)
+		if errors.Is(err, sql.ErrNoRows) || (err == nil && build.Project != slug) {
+			http.Error(rw, "404 Not Found", http.StatusNotFound)
+			return
+		} else if err != nil {
+			log.Println("Database Error:", err)
+			http.Error(rw, "Database Error", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	filename := build.Filename
+	if sha512 != build.Sha512 {
+		buildFiles, err := r.db.ListFilesForBuild(req.Context(), build.ID)
+		if err != nil {
+			log.Println("Database Error:", err)
+			http.Error(rw, "Database Error", http.StatusInternalServerError)
+			return
+		}
+		n := slices.IndexFunc(buildFiles, func(f database.BuildFile) bool { return f.Sha512 == sha512 })
+		if n == -1 {
+			http.Error(rw, "404 Not Found", http.StatusNotFound)
+			return
+		}
+		filename = buildFiles[n].Filename
+	}
+
+	cdnUrl, err := r.modrinthCdnUrl(req.Context(), project, build.ID, filename)
+	if err != nil {
+		log.Println("Database Error:", err)
+		http.Error(rw, "Database Error", http.StatusInternalServerError)
+		return
+	}
+	if cdnUrl != "" {
+		http.Redirect(rw, req, cdnUrl, http.StatusFound)
+		return
+	}
+
+	obj, err := r.storage.Get(req.Context(), sha512)
+	if errors.Is(err, storage.ErrNotExist) {
+		log.Println("Stored file is missing:", sha512)
+		http.Error(rw, "404 Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Println("Failed file loading:", err)
+		http.Error(rw, "Failed file loading", http.StatusInternalServerError)
+		return
+	}
+	defer obj.Close()
+
+	// files are stored by hash so the content behind a hash never changes
+	rw.Header().Set("ETag", `"`+sha512+`"`)
+	if latest {
+		rw.Header().Set("Cache-Control", "no-cache")
+	} else {
+		rw.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	}
+	rw.Header().Set("Content-Type", "application/java-archive")
+	rw.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	http.ServeContent(rw, req, "", obj.Info().ModTime, obj)
+}
+
+// modrinthCdnUrl returns the Modrinth CDN address of the file if downloads are
+// redirected and the build was published to the configured platform
+func (r routeCtx) modrinthCdnUrl(ctx context.Context, project mc_upload_api.Project, buildId int64, filename string) (string, error) {
+	cdn := r.configYml.Load().ModrinthCdn
+	platform, ok := project.Platforms[cdn.Platform]
+	if cdn.Platform == "" || !ok || !platform.Enabled() {
+		return "", nil
+	}
+	platformRows, err := r.db.ListPlatformsForBuild(ctx, buildId)
+	if err != nil {
+		return "", err
+	}
+	for _, i := range platformRows {
+		if i.Platform != cdn.Platform || i.RemoteID == "" {
+			continue
+		}
+		return url.JoinPath(cdn.BaseUrl(), "data", platform.Id, "versions", i.RemoteID, filename)
+	}
+	return "", nil
+}
+
+// latestBuild finds the newest build matching the loader, game_version and
+// channel query values
+func latestBuild(rows []database.ListBuildsRow, query url.Values) (database.ListBuildsRow, bool) {
+	loader := query.Get("loader")
+	gameVersion := query.Get("game_version")
+	channel := query.Get("channel")
+	for _, row := range slices.Backward(rows) {
+		switch {
+		case row.Meta == nil:
+		case loader != "" && !slices.Contains(row.Meta.Loaders, loader):
+		case gameVersion != "" && !slices.Contains(row.Meta.GameVersions, gameVersion):
+		case channel != "" && row.Meta.ReleaseChannel != channel:
+		default:
+			return row, true
+		}
+	}
+	return database.ListBuildsRow{}, false
+}
+
+// listedBuild converts a row from ListBuilds
+func listedBuild(slug string, row database.ListBuildsRow) database.Build {
+	return database.Build{
+		ID:         row.ID,
+		Project:    slug,
+		Meta:       row.Meta,
+		Filename:   row.Filename,
+		Sha512:     row.Sha512,
+		Changelog:  row.Changelog,
+		ReleaseID:  row.ReleaseID,
+		CreatedAt:  row.CreatedAt,
+		UploadedBy: row.UploadedBy,
+		GitCommit:  row.GitCommit,
+		GitRef:     row.GitRef,
+		CiRunUrl:   row.CiRunUrl,
+		Size:       row.Size,
+	}
+}
@@ -0,0 +1,94 @@
+package routes
+
+import (
+	"context"
+	"github.com/mrmelon54/mc-upload-api"
+	"github.com/mrmelon54/mc-upload-api/database"
+	"github.com/mrmelon54/mc-upload-api/database/types"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestModDownloadGet(t *testing.T) {
+	r := testRoutes(t)
+	sha := createTestBuild(t, r, types.BuildMeta{VersionNumber: "1.0.0"}, time.Now())
+	router := r.router()
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/mod/clock/download/"+sha, nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "clock 1.0.0", rec.Body.String())
+	assert.Equal(t, `"`+sha+`"`, rec.Header().Get("ETag"))
+	assert.Equal(t, "public, max-age=31536000, immutable", rec.Header().Get("Cache-Control"))
+	assert.Equal(t, "application/java-archive", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "attachment; filename=clock-1.0.0.jar", rec.Header().Get("Content-Disposition"))
+
+	req := httptest.NewRequest(http.MethodGet, "/mod/clock/download/"+sha, nil)
+	req.Header.Set("If-None-Match", `"`+sha+`"`)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/mod/clock/download/"+sha, nil)
+	req.Header.Set("Range", "bytes=6-")
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusPartialContent, rec.Code)
+	assert.Equal(t, "1.0.0", rec.Body.String())
+	assert.Equal(t, "bytes 6-10/11", rec.Header().Get("Content-Range"))
+
+	for _, i := range []string{"/mod/clock/download/" + sha[:len(sha)-1] + "0", "/mod/other/download/" + sha} {
+		rec = httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, i, nil))
+		assert.Equal(t, http.StatusNotFound, rec.Code, i)
+	}
+}
+
+func TestModDownloadGet_Latest(t *testing.T) {
+	r := testRoutes(t)
+	createHiddenBuilds(t, r)
+
+	rec := httptest.NewRecorder()
+	r.router().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/mod/clock/download/latest?loader=forge&game_version=1.20.1", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "clock 1.0.1", rec.Body.String())
+	assert.Equal(t, "no-cache", rec.Header().Get("Cache-Control"))
+
+	rec = httptest.NewRecorder()
+	r.router().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/mod/clock/download/latest?loader=fabric", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestModDownloadGet_ModrinthCdn(t *testing.T) {
+	r := testRoutes(t)
+	r.configYml.Store(&mc_upload_api.Config{ModrinthCdn: mc_upload_api.ModrinthCdn{Platform: "modrinth"}})
+	var project mc_upload_api.Project
+	project.Token = "abcd"
+	project.Platforms = map[string]mc_upload_api.ProjectPlatform{"modrinth": {Id: "AABBCCDD"}}
+	r.projectsYml.Store(&mc_upload_api.ProjectsConfig{"clock": project})
+	published := createTestBuild(t, r, types.BuildMeta{VersionNumber: "1.0.0"}, time.Now())
+	unpublished := createTestBuild(t, r, types.BuildMeta{VersionNumber: "1.1.0"}, time.Now())
+	build, err := r.db.GetBuildByFileHash(context.Background(), published)
+	assert.NoError(t, err)
+	assert.NoError(t, r.db.SetBuildPlatform(context.Background(), database.SetBuildPlatformParams{
+		BuildID:  build.ID,
+		Platform: "modrinth",
+		RemoteID: "abcd1234",
+		Url:      "https://modrinth.com/mod/clock/version/abcd1234",
+	}))
+
+	rec := httptest.NewRecorder()
+	r.router().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/mod/clock/download/"+published, nil))
+	assert.Equal(t, http.StatusFound, rec.Code)
+	assert.Equal(t, "https://cdn.modrinth.com/data/AABBCCDD/versions/abcd1234/clock-1.0.0.jar", rec.Header().Get("Location"))
+
+	// builds which were not published to modrinth are served from storage
+	rec = httptest.NewRecorder()
+	r.router().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/mod/clock/download/"+unpublished, nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "clock 1.1.0", rec.Body.String())
+}
@@ -0,0 +1,62 @@
+package routes
+
+import (
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"github.com/mrmelon54/mc-upload-api/database"
+	"log"
+	"net/http"
+	"time"
+)
+
+// forgeLoaders are the loaders which read the update feed
+var forgeLoaders = []string{"forge", "neoforge"}
+
+// modForgeUpdateGet serves the feed for the Forge `updateJSONURL` option
+func (r routeCtx) modForgeUpdateGet(rw http.ResponseWriter, req *http.Request, params httprouter.Params) {
+	slug := params.ByName("slug")
+	project, ok := (*r.projectsYml.Load())[slug]
+	if !ok {
+		http.Error(rw, "404 Not Found", http.StatusNotFound)
+		return
+	}
+	rows, err := r.db.ListBuilds(req.Context(), slug)
+	if err != nil {
+		log.Println("Database Error:", err)
+		http.Error(rw, "Database Error", http.StatusInternalServerError)
+		return
+	}
+	rw.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(rw).Encode(forgeUpdateFeed(project.Homepage, listedBuilds(project, rows, time.Now())))
+}
+
+// forgeUpdateFeed maps game versions to the changelog of each mod version, the
+// promos point to the newest build and the newest release build
+func forgeUpdateFeed(homepage string, rows []database.ListBuildsRow) map[string]any {
+	feed := make(map[string]any)
+	promos := make(map[string]string)
+	changelogs := make(map[string]map[string]string)
+	for _, row := range rows {
+		if row.Meta == nil || !containsAny(row.Meta.Loaders, forgeLoaders) {
+			continue
+		}
+		for _, mc := range row.Meta.GameVersions {
+			if changelogs[mc] == nil {
+				changelogs[mc] = make(map[string]string)
+			}
+			changelogs[mc][row.Meta.VersionNumber] = row.Changelog
+			promos[mc+"-latest"] = row.Meta.VersionNumber
+			if row.Meta.ReleaseChannel == "release" {
+				promos[mc+"-recommended"] = row.Meta.VersionNumber
+			}
+		}
+	}
+	for k, v := range changelogs {
+		feed[k] = v
+	}
+	if homepage != "" {
+		feed["homepage"] = homepage
+	}
+	feed["promos"] = promos
+	return feed
+}
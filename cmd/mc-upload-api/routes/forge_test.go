@@ -0,0 +1,84 @@
+package routes
+
+import (
+	"encoding/json"
+	"github.com/mrmelon54/mc-upload-api/database"
+	"github.com/mrmelon54/mc-upload-api/database/types"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func forgeRow(version, channel string, loaders []string, gameVersions ...string) database.ListBuildsRow {
+	return database.ListBuildsRow{
+		Meta:      &types.BuildMeta{VersionNumber: version, ReleaseChannel: channel, Loaders: loaders, GameVersions: gameVersions},
+		Changelog: "changes in " + version,
+	}
+}
+
+func TestForgeUpdateFeed(t *testing.T) {
+	forge := []string{"forge"}
+	for _, i := range []struct {
+		name     string
+		homepage string
+		rows     []database.ListBuildsRow
+		feed     map[string]any
+	}{
+		{
+			name: "empty",
+			feed: map[string]any{"promos": map[string]string{}},
+		},
+		{
+			name:     "promos per game version",
+			homepage: "https://example.com/clock",
+			rows: []database.ListBuildsRow{
+				forgeRow("1.0.0", "release", forge, "1.20.1", "1.20.2"),
+				forgeRow("1.1.0-beta", "beta", []string{"neoforge"}, "1.20.2"),
+				forgeRow("1.1.0", "release", forge, "1.20.1"),
+				{Changelog: "no metadata"},
+			},
+			feed: map[string]any{
+				"homepage": "https://example.com/clock",
+				"1.20.1":   map[string]string{"1.0.0": "changes in 1.0.0", "1.1.0": "changes in 1.1.0"},
+				"1.20.2":   map[string]string{"1.0.0": "changes in 1.0.0", "1.1.0-beta": "changes in 1.1.0-beta"},
+				"promos": map[string]string{
+					"1.20.1-latest":      "1.1.0",
+					"1.20.1-recommended": "1.1.0",
+					"1.20.2-latest":      "1.1.0-beta",
+					"1.20.2-recommended": "1.0.0",
+				},
+			},
+		},
+		{
+			name: "fabric builds are skipped",
+			rows: []database.ListBuildsRow{
+				forgeRow("1.0.0", "release", forge, "1.20.1"),
+				forgeRow("1.1.0", "release", []string{"fabric"}, "1.20.1", "1.20.4"),
+			},
+			feed: map[string]any{
+				"1.20.1": map[string]string{"1.0.0": "changes in 1.0.0"},
+				"promos": map[string]string{"1.20.1-latest": "1.0.0", "1.20.1-recommended": "1.0.0"},
+			},
+		},
+	} {
+		t.Run(i.name, func(t *testing.T) {
+			assert.Equal(t, i.feed, forgeUpdateFeed(i.homepage, i.rows))
+		})
+	}
+}
+
+func TestModForgeUpdateGet_Hidden(t *testing.T) {
+	r := testRoutes(t)
+	createHiddenBuilds(t, r)
+
+	rec := httptest.NewRecorder()
+	r.router().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/mod/clock/forge-update.json", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var feed map[string]any
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&feed))
+	assert.Equal(t, map[string]any{
+		"1.20.1": map[string]any{"1.0.0": "changes in 1.0.0", "1.0.1": "changes in 1.0.1"},
+		"promos": map[string]any{"1.20.1-latest": "1.0.1", "1.20.1-recommended": "1.0.1"},
+	}, feed)
+}
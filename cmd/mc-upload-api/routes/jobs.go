@@ -0,0 +1,58 @@
+package routes
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"github.com/julienschmidt/httprouter"
+	"github.com/mrmelon54/mc-upload-api/database"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+type jobStatus struct {
+	database.GetJobRow
+
+	// Platforms maps platform names to the publishing result of the build
+	Platforms map[string]platformResult `json:"platforms"`
+}
+
+func (r routeCtx) jobGet(rw http.ResponseWriter, req *http.Request, params httprouter.Params) {
+	jobId, err := strconv.ParseInt(params.ByName("id"), 10, 64)
+	if err != nil {
+		http.Error(rw, "404 Not Found", http.StatusNotFound)
+		return
+	}
+	job, err := r.db.GetJob(req.Context(), jobId)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(rw, "404 Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Println("Database Error:", err)
+		http.Error(rw, "Database Error", http.StatusInternalServerError)
+		return
+	}
+	project, ok := (*r.projectsYml.Load())[job.Project]
+	if !ok {
+		http.Error(rw, "404 Not Found", http.StatusNotFound)
+		return
+	}
+	if !checkProjectToken(project, req) {
+		http.Error(rw, "403 Forbidden", http.StatusForbidden)
+		return
+	}
+
+	platformRows, err := r.db.ListPlatformsForBuild(req.Context(), job.BuildID)
+	if err != nil {
+		log.Println("Database Error:", err)
+		http.Error(rw, "Database Error", http.StatusInternalServerError)
+		return
+	}
+	status := jobStatus{GetJobRow: job, Platforms: make(map[string]platformResult)}
+	for _, i := range platformRows {
+		status.Platforms[i.Platform] = newPlatformResult(i)
+	}
+	rw.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(rw).Encode(status)
+}
@@ -0,0 +1,42 @@
+package routes
+
+import (
+	"github.com/mrmelon54/mc-upload-api"
+	"github.com/mrmelon54/mc-upload-api/database"
+	"github.com/mrmelon54/mc-upload-api/database/types"
+	"time"
+)
+
+// buildListed reports whether the build is public at now, drafts, unlisted
+// builds and builds scheduled for later are hidden
+//
+// The status and schedule of the build replace the Modrinth release defaults of
+// the project, a schedule delay is counted from the upload.
+func buildListed(project mc_upload_api.Project, meta *types.BuildMeta, createdAt int64, now time.Time) bool {
+	if meta == nil {
+		return false
+	}
+	modrinth := project.Platforms["modrinth"]
+	status := meta.Status
+	if status == "" {
+		status = modrinth.Status
+	}
+	if status != "" && status != "listed" {
+		return false
+	}
+	if meta.ScheduledAt != nil {
+		return !meta.ScheduledAt.After(now)
+	}
+	return !time.Unix(createdAt, 0).Add(modrinth.ScheduleDelay).After(now)
+}
+
+// listedBuilds removes the builds which are not public at now
+func listedBuilds(project mc_upload_api.Project, rows []database.ListBuildsRow, now time.Time) []database.ListBuildsRow {
+	a := make([]database.ListBuildsRow, 0, len(rows))
+	for _, row := range rows {
+		if buildListed(project, row.Meta, row.CreatedAt, now) {
+			a = append(a, row)
+		}
+	}
+	return a
+}
@@ -0,0 +1,44 @@
+package routes
+
+import (
+	"github.com/mrmelon54/mc-upload-api"
+	"github.com/mrmelon54/mc-upload-api/database/types"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestBuildListed(t *testing.T) {
+	now := time.Now()
+	past, future := now.Add(-time.Minute), now.Add(time.Minute)
+	var project mc_upload_api.Project
+	var drafts mc_upload_api.Project
+	drafts.Platforms = map[string]mc_upload_api.ProjectPlatform{"modrinth": {Id: "clock", Status: "draft"}}
+	var delayed mc_upload_api.Project
+	delayed.Platforms = map[string]mc_upload_api.ProjectPlatform{"modrinth": {Id: "clock", ScheduleDelay: time.Hour}}
+	for _, i := range []struct {
+		name      string
+		project   mc_upload_api.Project
+		meta      *types.BuildMeta
+		createdAt time.Time
+		listed    bool
+	}{
+		{name: "no metadata", project: project, createdAt: past},
+		{name: "listed", project: project, meta: &types.BuildMeta{}, createdAt: past, listed: true},
+		{name: "listed status", project: project, meta: &types.BuildMeta{Status: "listed"}, createdAt: past, listed: true},
+		{name: "draft", project: project, meta: &types.BuildMeta{Status: "draft"}, createdAt: past},
+		{name: "unlisted", project: project, meta: &types.BuildMeta{Status: "unlisted"}, createdAt: past},
+		{name: "archived", project: project, meta: &types.BuildMeta{Status: "archived"}, createdAt: past},
+		{name: "scheduled before now", project: project, meta: &types.BuildMeta{ScheduledAt: &past}, createdAt: past, listed: true},
+		{name: "scheduled after now", project: project, meta: &types.BuildMeta{ScheduledAt: &future}, createdAt: past},
+		{name: "draft default", project: drafts, meta: &types.BuildMeta{}, createdAt: past},
+		{name: "listed over draft default", project: drafts, meta: &types.BuildMeta{Status: "listed"}, createdAt: past, listed: true},
+		{name: "schedule delay", project: delayed, meta: &types.BuildMeta{}, createdAt: past},
+		{name: "schedule delay passed", project: delayed, meta: &types.BuildMeta{}, createdAt: now.Add(-2 * time.Hour), listed: true},
+		{name: "scheduled over schedule delay", project: delayed, meta: &types.BuildMeta{ScheduledAt: &past}, createdAt: past, listed: true},
+	} {
+		t.Run(i.name, func(t *testing.T) {
+			assert.Equal(t, i.listed, buildListed(i.project, i.meta, i.createdAt.Unix(), now))
+		})
+	}
+}
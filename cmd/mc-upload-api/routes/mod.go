@@ -31,8 +31,48 @@ func (r routeCtx) modVersionsGet(rw http.ResponseWriter, req *http.Request, para
 		http.Error(rw, "Database Error", http.StatusInternalServerError)
 		return
 	}
-	if rows == nil {
-		rows = []database.ListBuildsRow{}
+	platformRows, err := r.db.ListBuildPlatforms(req.Context(), slug)
+	if err != nil {
+		log.Println("Database Error:", err)
+		http.Error(rw, "Database Error", http.StatusInternalServerError)
+		return
+	}
+	fileRows, err := r.db.ListBuildFiles(req.Context(), slug)
+	if err != nil {
+		log.Println("Database Error:", err)
+		http.Error(rw, "Database Error", http.StatusInternalServerError)
+		return
+	}
+	files := make(map[int64][]database.BuildFile)
+	for _, i := range fileRows {
+		files[i.BuildID] = append(files[i.BuildID], i)
+	}
+	platforms := make(map[int64]map[string]platformResult)
+	for _, i := range platformRows {
+		if platforms[i.BuildID] == nil {
+			platforms[i.BuildID] = make(map[string]platformResult)
+		}
+		platforms[i.BuildID][i.Platform] = newPlatformResult(i)
+	}
+	versions := make([]buildVersion, len(rows))
+	for i := range rows {
+		versions[i] = buildVersion{ListBuildsRow: rows[i], Files: files[rows[i].ID], Platforms: platforms[rows[i].ID]}
+		if versions[i].Files == nil {
+			versions[i].Files = []database.BuildFile{}
+		}
+		if versions[i].Platforms == nil {
+			versions[i].Platforms = map[string]platformResult{}
+		}
 	}
-	_ = json.NewEncoder(rw).Encode(rows)
+	_ = json.NewEncoder(rw).Encode(versions)
+}
+
+type buildVersion struct {
+	database.ListBuildsRow
+
+	// Files are the additional files stored with the build
+	Files []database.BuildFile `json:"files"`
+
+	// Platforms maps platform names to the publishing result of the build
+	Platforms map[string]platformResult `json:"platforms"`
 }
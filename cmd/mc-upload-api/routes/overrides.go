@@ -0,0 +1,131 @@
+package routes
+
+import (
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	"github.com/mrmelon54/mc-upload-api"
+	jarparser "github.com/mrmelon54/mc-upload-api/jar-parser"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	releaseChannels = []string{"release", "beta", "alpha"}
+	environments    = []string{"*", "client", "server", "both"}
+	versionStatuses = []string{"listed", "archived", "draft", "unlisted"}
+)
+
+// uploadOverrides are the optional upload fields applied to the parsed metadata
+type uploadOverrides struct {
+	// Name is the display name of the version, from the name field or the
+	// project version_name template
+	Name string
+
+	// Featured, Status and ScheduledAt are the release options for Modrinth
+	Featured    *bool
+	Status      string
+	ScheduledAt *time.Time
+
+	// Fields lists the names of the fields which were set
+	Fields []string
+}
+
+// formList reads a field which can be repeated or contain comma separated values
+func formList(values url.Values, key string) []string {
+	var a []string
+	for _, value := range values[key] {
+		for _, i := range strings.Split(value, ",") {
+			if i = strings.TrimSpace(i); i != "" {
+				a = append(a, i)
+			}
+		}
+	}
+	return a
+}
+
+// applyOverrides replaces the parsed metadata with the upload fields, game
+// versions are added to the range declared by the jar
+//
+// The release channel comes from the release_channel field, then the first
+// matching project rule and finally the prerelease part of the version number.
+func (r routeCtx) applyOverrides(values url.Values, project mc_upload_api.Project, meta *jarparser.ModMetadata) (uploadOverrides, error) {
+	var o uploadOverrides
+	if v := strings.TrimSpace(values.Get("version_number")); v != "" {
+		meta.VersionNumber = v
+		meta.ReleaseChannel = jarparser.ReleaseChannelFromVersion(v)
+		o.Fields = append(o.Fields, "version_number")
+	}
+	if channel, ok := project.ReleaseChannel(meta.VersionNumber); ok {
+		meta.ReleaseChannel = channel
+	}
+	if v := strings.TrimSpace(values.Get("release_channel")); v != "" {
+		if !slices.Contains(releaseChannels, v) {
+			return o, fmt.Errorf("invalid release channel: %s", v)
+		}
+		meta.ReleaseChannel = v
+		o.Fields = append(o.Fields, "release_channel")
+	}
+	if a := formList(values, "game_versions"); len(a) > 0 {
+		for _, i := range a {
+			ver, err := semver.NewVersion(i)
+			if err != nil {
+				return o, fmt.Errorf("invalid game version: %s", i)
+			}
+			c, err := semver.NewConstraint("=" + ver.String())
+			if err != nil {
+				return o, fmt.Errorf("invalid game version: %s", i)
+			}
+			if len(r.mcVersions.MatchingConstraints(c)) == 0 {
+				return o, fmt.Errorf("unknown game version: %s", i)
+			}
+			meta.GameVersions = append(meta.GameVersions, c)
+		}
+		o.Fields = append(o.Fields, "game_versions")
+	}
+	if a := formList(values, "loaders"); len(a) > 0 {
+		meta.Loaders = a
+		o.Fields = append(o.Fields, "loaders")
+	}
+	if v := strings.TrimSpace(values.Get("environment")); v != "" {
+		if !slices.Contains(environments, v) {
+			return o, fmt.Errorf("invalid environment: %s", v)
+		}
+		meta.Environment = v
+		o.Fields = append(o.Fields, "environment")
+	}
+	if v := strings.TrimSpace(values.Get("name")); v != "" {
+		o.Name = v
+		o.Fields = append(o.Fields, "name")
+	}
+	if v := strings.TrimSpace(values.Get("featured")); v != "" {
+		featured, err := strconv.ParseBool(v)
+		if err != nil {
+			return o, fmt.Errorf("invalid featured: %s", v)
+		}
+		o.Featured = &featured
+		o.Fields = append(o.Fields, "featured")
+	}
+	if v := strings.TrimSpace(values.Get("status")); v != "" {
+		if !slices.Contains(versionStatuses, v) {
+			return o, fmt.Errorf("invalid status: %s", v)
+		}
+		o.Status = v
+		o.Fields = append(o.Fields, "status")
+	}
+	if v := strings.TrimSpace(values.Get("scheduled_at")); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return o, fmt.Errorf("invalid scheduled_at: %s", v)
+		}
+		if !t.After(time.Now()) {
+			return o, fmt.Errorf("scheduled_at must be in the future")
+		}
+		t = t.UTC()
+		o.ScheduledAt = &t
+		o.Fields = append(o.Fields, "scheduled_at")
+	}
+	return o, nil
+}
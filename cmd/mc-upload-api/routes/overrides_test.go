@@ -0,0 +1,153 @@
+package routes
+
+import (
+	"github.com/Masterminds/semver/v3"
+	"github.com/mrmelon54/mc-upload-api"
+	jarparser "github.com/mrmelon54/mc-upload-api/jar-parser"
+	resolveversions "github.com/mrmelon54/mc-upload-api/resolve-versions"
+	"github.com/mrmelon54/mc-upload-api/uploader/test"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"testing"
+)
+
+func testMcVersions() *resolveversions.McVersions {
+	return resolveversions.NewMcVersionCache(&http.Client{
+		Transport: test.RoundTripFunc(func(req *http.Request) *http.Response {
+			rec := httptest.NewRecorder()
+			rec.WriteHeader(http.StatusOK)
+			rec.WriteString(`{"versions":[{"id":"1.20.1"},{"id":"1.20.2"},{"id":"1.20.4"}]}`)
+			return rec.Result()
+		}),
+	})
+}
+
+func TestApplyOverrides(t *testing.T) {
+	r := routeCtx{mcVersions: testMcVersions()}
+	snapshots := mc_upload_api.Project{ReleaseChannels: []mc_upload_api.ReleaseChannelRule{
+		{Match: mc_upload_api.Regexp{Regexp: regexp.MustCompile(`-snapshot$`)}, Channel: "alpha"},
+	}}
+	for _, i := range []struct {
+		name         string
+		values       url.Values
+		project      mc_upload_api.Project
+		err          string
+		fields       []string
+		version      string
+		channel      string
+		gameVersions []string
+		loaders      []string
+		environment  string
+	}{
+		{
+			name:         "no overrides",
+			version:      "1.0.0",
+			channel:      "release",
+			gameVersions: []string{">=1.20"},
+			loaders:      []string{"fabric"},
+			environment:  "*",
+		},
+		{
+			name:         "version number",
+			values:       url.Values{"version_number": {" 1.1.0-beta.2 "}},
+			fields:       []string{"version_number"},
+			version:      "1.1.0-beta.2",
+			channel:      "beta",
+			gameVersions: []string{">=1.20"},
+			loaders:      []string{"fabric"},
+			environment:  "*",
+		},
+		{
+			name:         "version number matching a project rule",
+			values:       url.Values{"version_number": {"1.1.0-snapshot"}},
+			project:      snapshots,
+			fields:       []string{"version_number"},
+			version:      "1.1.0-snapshot",
+			channel:      "alpha",
+			gameVersions: []string{">=1.20"},
+			loaders:      []string{"fabric"},
+			environment:  "*",
+		},
+		{
+			name:         "loaders",
+			values:       url.Values{"loaders": {"fabric, quilt", "neoforge"}},
+			fields:       []string{"loaders"},
+			version:      "1.0.0",
+			channel:      "release",
+			gameVersions: []string{">=1.20"},
+			loaders:      []string{"fabric", "quilt", "neoforge"},
+			environment:  "*",
+		},
+		{
+			name:         "environment",
+			values:       url.Values{"environment": {"client"}},
+			fields:       []string{"environment"},
+			version:      "1.0.0",
+			channel:      "release",
+			gameVersions: []string{">=1.20"},
+			loaders:      []string{"fabric"},
+			environment:  "client",
+		},
+		{
+			name:   "invalid environment",
+			values: url.Values{"environment": {"browser"}},
+			err:    "invalid environment: browser",
+		},
+		{
+			name:         "game versions",
+			values:       url.Values{"game_versions": {"1.20.1,1.20.4"}},
+			fields:       []string{"game_versions"},
+			version:      "1.0.0",
+			channel:      "release",
+			gameVersions: []string{">=1.20", "=1.20.1", "=1.20.4"},
+			loaders:      []string{"fabric"},
+			environment:  "*",
+		},
+		{
+			name:   "invalid game version",
+			values: url.Values{"game_versions": {"1.20.1,latest"}},
+			err:    "invalid game version: latest",
+		},
+		{
+			name:   "unknown game version",
+			values: url.Values{"game_versions": {"1.20.3"}},
+			err:    "unknown game version: 1.20.3",
+		},
+		{
+			name:   "invalid release channel",
+			values: url.Values{"release_channel": {"nightly"}},
+			err:    "invalid release channel: nightly",
+		},
+	} {
+		t.Run(i.name, func(t *testing.T) {
+			c, err := semver.NewConstraint(">=1.20")
+			assert.NoError(t, err)
+			meta := jarparser.ModMetadata{
+				VersionNumber:  "1.0.0",
+				ReleaseChannel: "release",
+				GameVersions:   []*semver.Constraints{c},
+				Loaders:        []string{"fabric"},
+				Environment:    "*",
+			}
+			o, err := r.applyOverrides(i.values, i.project, &meta)
+			if i.err != "" {
+				assert.EqualError(t, err, i.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, i.fields, o.Fields)
+			assert.Equal(t, i.version, meta.VersionNumber)
+			assert.Equal(t, i.channel, meta.ReleaseChannel)
+			var gameVersions []string
+			for _, c := range meta.GameVersions {
+				gameVersions = append(gameVersions, c.String())
+			}
+			assert.Equal(t, i.gameVersions, gameVersions)
+			assert.Equal(t, i.loaders, meta.Loaders)
+			assert.Equal(t, i.environment, meta.Environment)
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package routes
+
+import (
+	"fmt"
+	"github.com/mrmelon54/mc-upload-api"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// gitCommitPattern matches full and abbreviated SHA-1 and SHA-256 commit hashes
+var gitCommitPattern = regexp.MustCompile(`^[0-9a-fA-F]{4,64}$`)
+
+// maxProvenanceSize limits the length of the git_ref and ci_run_url fields
+const maxProvenanceSize = 2048
+
+// provenance records who uploaded a build and where it was built
+type provenance struct {
+	UploadedBy string
+	GitCommit  string
+	GitRef     string
+	CiRunUrl   string
+}
+
+// readProvenance reads the optional git_commit, git_ref and ci_run_url fields
+func readProvenance(values url.Values, project mc_upload_api.Project) (provenance, error) {
+	p := provenance{UploadedBy: project.Uploader}
+	if v := strings.TrimSpace(values.Get("git_commit")); v != "" {
+		if !gitCommitPattern.MatchString(v) {
+			return p, fmt.Errorf("invalid git_commit: %s", v)
+		}
+		p.GitCommit = strings.ToLower(v)
+	}
+	if v := strings.TrimSpace(values.Get("git_ref")); v != "" {
+		if len(v) > maxProvenanceSize || strings.ContainsFunc(v, func(r rune) bool { return unicode.IsSpace(r) || unicode.IsControl(r) }) {
+			return p, fmt.Errorf("invalid git_ref: %s", v)
+		}
+		p.GitRef = v
+	}
+	if v := strings.TrimSpace(values.Get("ci_run_url")); v != "" {
+		u, err := url.Parse(v)
+		if err != nil || len(v) > maxProvenanceSize || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return p, fmt.Errorf("invalid ci_run_url: %s", v)
+		}
+		p.CiRunUrl = u.String()
+	}
+	return p, nil
+}
@@ -0,0 +1,50 @@
+package routes
+
+import (
+	"github.com/mrmelon54/mc-upload-api"
+	"github.com/stretchr/testify/assert"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestReadProvenance(t *testing.T) {
+	for _, i := range []struct {
+		name   string
+		values url.Values
+		commit string
+		ref    string
+		ciRun  string
+		err    string
+	}{
+		{name: "empty", values: url.Values{}},
+		{name: "short commit", values: url.Values{"git_commit": {"ABC1234"}}, commit: "abc1234"},
+		{name: "sha1 commit", values: url.Values{"git_commit": {strings.Repeat("a1", 20)}}, commit: strings.Repeat("a1", 20)},
+		{name: "sha256 commit", values: url.Values{"git_commit": {strings.Repeat("b2", 32)}}, commit: strings.Repeat("b2", 32)},
+		{name: "commit too short", values: url.Values{"git_commit": {"abc"}}, err: "invalid git_commit: abc"},
+		{name: "commit too long", values: url.Values{"git_commit": {strings.Repeat("a", 65)}}, err: "invalid git_commit: " + strings.Repeat("a", 65)},
+		{name: "commit not hex", values: url.Values{"git_commit": {"abcdefg"}}, err: "invalid git_commit: abcdefg"},
+		{
+			name:   "ref and ci run",
+			values: url.Values{"git_ref": {"refs/heads/main"}, "ci_run_url": {"https://ci.example.com/runs/1"}},
+			ref:    "refs/heads/main",
+			ciRun:  "https://ci.example.com/runs/1",
+		},
+		{name: "ref with space", values: url.Values{"git_ref": {"refs/heads/a b"}}, err: "invalid git_ref: refs/heads/a b"},
+		{name: "ci run not http", values: url.Values{"ci_run_url": {"ftp://ci.example.com"}}, err: "invalid ci_run_url: ftp://ci.example.com"},
+		{name: "ci run without host", values: url.Values{"ci_run_url": {"https:///runs/1"}}, err: "invalid ci_run_url: https:///runs/1"},
+	} {
+		t.Run(i.name, func(t *testing.T) {
+			p, err := readProvenance(i.values, mc_upload_api.Project{Token: "secret", Uploader: "ci"})
+			if i.err != "" {
+				assert.EqualError(t, err, i.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "ci", p.UploadedBy)
+			assert.Equal(t, i.commit, p.GitCommit)
+			assert.Equal(t, i.ref, p.GitRef)
+			assert.Equal(t, i.ciRun, p.CiRunUrl)
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/mrmelon54/mc-upload-api/database"
+	"github.com/mrmelon54/mc-upload-api/publisher"
+	"net/http"
+)
+
+const (
+	statusSuccess = "success"
+	statusFailed  = "failed"
+)
+
+type platformResult struct {
+	Status   string   `json:"status"`
+	Id       string   `json:"id,omitempty"`
+	Url      string   `json:"url,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
+
+func newPlatformResult(row database.BuildPlatform) platformResult {
+	if row.Error != "" {
+		return platformResult{Status: statusFailed, Error: row.Error}
+	}
+	return platformResult{Status: statusSuccess, Id: row.RemoteID, Url: row.Url, Warnings: row.Warnings}
+}
+
+type jobAccepted struct {
+	JobId   int64  `json:"job_id"`
+	BuildId int64  `json:"build_id"`
+	Sha512  string `json:"sha512"`
+	Status  string `json:"status"`
+}
+
+// writeJobAccepted responds with 202 Accepted and the location to poll for the job status
+func writeJobAccepted(rw http.ResponseWriter, jobId, buildId int64, sha512 string) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.Header().Set("Location", fmt.Sprintf("/jobs/%d", jobId))
+	rw.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(rw).Encode(jobAccepted{
+		JobId:   jobId,
+		BuildId: buildId,
+		Sha512:  sha512,
+		Status:  publisher.JobPending,
+	})
+}
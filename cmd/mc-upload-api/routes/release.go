@@ -0,0 +1,250 @@
+package routes
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"github.com/mrmelon54/mc-upload-api"
+	"github.com/mrmelon54/mc-upload-api/database"
+	"github.com/mrmelon54/mc-upload-api/database/types"
+	jarparser "github.com/mrmelon54/mc-upload-api/jar-parser"
+	"github.com/mrmelon54/mc-upload-api/publisher"
+	resolveversions "github.com/mrmelon54/mc-upload-api/resolve-versions"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// MaxReleaseJars limits the number of jars in a release
+const MaxReleaseJars = 8
+
+// releaseJarFields are the overrides which describe a single jar, every jar in
+// a release uses its own metadata instead
+var releaseJarFields = []string{"version_number", "game_versions", "loaders", "environment", "name"}
+
+type releaseAccepted struct {
+	ReleaseId int64         `json:"release_id"`
+	Builds    []jobAccepted `json:"builds"`
+}
+
+type releaseStatus struct {
+	database.Release
+	Builds []releaseBuild `json:"builds"`
+}
+
+type releaseBuild struct {
+	database.Build
+
+	// Platforms maps platform names to the publishing result of the build
+	Platforms map[string]platformResult `json:"platforms"`
+}
+
+// releasePost uploads several jars as one release, each jar is published as its
+// own version and the release is rejected if any jar is invalid
+func (r routeCtx) releasePost(rw http.ResponseWriter, req *http.Request, params httprouter.Params) {
+	slug := params.ByName("slug")
+	project, ok := (*r.projectsYml.Load())[slug]
+	if !ok {
+		http.Error(rw, "404 Not Found", http.StatusNotFound)
+		return
+	}
+	if !checkProjectToken(project, req) {
+		http.Error(rw, "403 Forbidden", http.StatusForbidden)
+		return
+	}
+
+	form, ok := r.readProjectUpload(rw, req, project, MaxReleaseJars)
+	if !ok {
+		return
+	}
+	defer form.Close()
+	if len(form.Files) > 0 {
+		http.Error(rw, "Additional files are not supported in releases", http.StatusBadRequest)
+		return
+	}
+	for _, i := range releaseJarFields {
+		if form.Values.Has(i) {
+			http.Error(rw, fmt.Sprintf("%s is not supported in releases", i), http.StatusBadRequest)
+			return
+		}
+	}
+
+	origin, err := readProvenance(form.Values, project)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	jars := form.Uploads()
+	metas := make([]*types.BuildMeta, len(jars))
+	seen := make(map[string]bool)
+	for n, jar := range jars {
+		if seen[jar.Sha512] {
+			http.Error(rw, fmt.Sprintf("Duplicate file: %s", jar.Filename), http.StatusBadRequest)
+			return
+		}
+		seen[jar.Sha512] = true
+
+		modMeta, err := jarparser.JarParser(jar.File, jar.Size)
+		if err != nil {
+			http.Error(rw, fmt.Sprintf("Failed to parse JAR: %s: %s", jar.Filename, err), http.StatusBadRequest)
+			return
+		}
+		overrides, err := r.applyOverrides(form.Values, project, &modMeta)
+		if err != nil {
+			http.Error(rw, fmt.Sprintf("%s: %s", jar.Filename, err), http.StatusBadRequest)
+			return
+		}
+		gameVersions, err := resolveversions.ResolveGameVersions(modMeta.GameVersions, r.mcVersions)
+		if err != nil {
+			log.Println("Failed to resolve game versions:", err)
+			http.Error(rw, "Failed to resolve game versions", http.StatusInternalServerError)
+			return
+		}
+		if overrides.Name == "" {
+			overrides.Name, err = project.RenderVersionName(mc_upload_api.NewVersionNameData(modMeta, gameVersions, jar.Filename))
+			if err != nil {
+				log.Println("Failed to render version name:", err)
+				http.Error(rw, "Failed to render version name", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		hashExists, err := r.db.HashExists(req.Context(), jar.Sha512)
+		if err != nil {
+			log.Println("Failed to check if hash already exists:", err)
+			http.Error(rw, "Failed to check if hash already exists", http.StatusInternalServerError)
+			return
+		}
+		if hashExists == 1 {
+			http.Error(rw, fmt.Sprintf("This hash is already uploaded: %s", jar.Filename), http.StatusConflict)
+			return
+		}
+		metas[n] = newBuildMeta(modMeta, gameVersions, overrides)
+	}
+
+	if err := r.storeUploads(req.Context(), jars); err != nil {
+		r.removeStored(req.Context(), jars)
+		log.Println("Failed file saving:", err)
+		http.Error(rw, "Failed file saving", http.StatusInternalServerError)
+		return
+	}
+
+	var releaseId int64
+	buildIds := make([]int64, len(jars))
+	jobIds := make([]int64, len(jars))
+	now := time.Now().Unix()
+	err = r.db.InTx(req.Context(), func(q *database.Queries) error {
+		var err error
+		releaseId, err = q.CreateRelease(req.Context(), database.CreateReleaseParams{
+			Project:   slug,
+			CreatedAt: now,
+		})
+		if err != nil {
+			return err
+		}
+		for n, jar := range jars {
+			buildIds[n], err = r.createBuild(req.Context(), q, database.CreateBuildParams{
+				Project:    slug,
+				Meta:       metas[n],
+				Filename:   jar.Filename,
+				Sha512:     jar.Sha512,
+				Size:       jar.Size,
+				Changelog:  form.Changelog,
+				ReleaseID:  releaseId,
+				CreatedAt:  now,
+				UploadedBy: origin.UploadedBy,
+				GitCommit:  origin.GitCommit,
+				GitRef:     origin.GitRef,
+				CiRunUrl:   origin.CiRunUrl,
+			}, nil)
+			if err != nil {
+				return err
+			}
+			jobIds[n], err = publisher.CreateJob(req.Context(), q, buildIds[n], "")
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		r.removeStored(req.Context(), jars)
+	}
+	if database.IsUniqueViolation(err) {
+		http.Error(rw, "This hash is already uploaded", http.StatusConflict)
+		return
+	} else if err != nil {
+		log.Println("Failed to create release:", err)
+		http.Error(rw, "Failed to create release", http.StatusInternalServerError)
+		return
+	}
+
+	r.queue.Wake()
+
+	accepted := releaseAccepted{ReleaseId: releaseId, Builds: make([]jobAccepted, 0, len(jars))}
+	for n, jar := range jars {
+		accepted.Builds = append(accepted.Builds, jobAccepted{
+			JobId:   jobIds[n],
+			BuildId: buildIds[n],
+			Sha512:  jar.Sha512,
+			Status:  publisher.JobPending,
+		})
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.Header().Set("Location", fmt.Sprintf("/releases/%d", releaseId))
+	rw.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(rw).Encode(accepted)
+}
+
+func (r routeCtx) releaseGet(rw http.ResponseWriter, req *http.Request, params httprouter.Params) {
+	releaseId, err := strconv.ParseInt(params.ByName("id"), 10, 64)
+	if err != nil {
+		http.Error(rw, "404 Not Found", http.StatusNotFound)
+		return
+	}
+	release, err := r.db.GetRelease(req.Context(), releaseId)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(rw, "404 Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Println("Database Error:", err)
+		http.Error(rw, "Database Error", http.StatusInternalServerError)
+		return
+	}
+	project, ok := (*r.projectsYml.Load())[release.Project]
+	if !ok {
+		http.Error(rw, "404 Not Found", http.StatusNotFound)
+		return
+	}
+	if !checkProjectToken(project, req) {
+		http.Error(rw, "403 Forbidden", http.StatusForbidden)
+		return
+	}
+
+	builds, err := r.db.ListReleaseBuilds(req.Context(), release.ID)
+	if err != nil {
+		log.Println("Database Error:", err)
+		http.Error(rw, "Database Error", http.StatusInternalServerError)
+		return
+	}
+	status := releaseStatus{Release: release, Builds: make([]releaseBuild, len(builds))}
+	for n, build := range builds {
+		platformRows, err := r.db.ListPlatformsForBuild(req.Context(), build.ID)
+		if err != nil {
+			log.Println("Database Error:", err)
+			http.Error(rw, "Database Error", http.StatusInternalServerError)
+			return
+		}
+		status.Builds[n] = releaseBuild{Build: build, Platforms: make(map[string]platformResult)}
+		for _, i := range platformRows {
+			status.Builds[n].Platforms[i.Platform] = newPlatformResult(i)
+		}
+	}
+	rw.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(rw).Encode(status)
+}
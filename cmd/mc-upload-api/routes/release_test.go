@@ -0,0 +1,100 @@
+package routes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func releaseRequest(t *testing.T, values map[string]string, jars ...string) *http.Request {
+	body := new(bytes.Buffer)
+	mw := multipart.NewWriter(body)
+	for k, v := range values {
+		assert.NoError(t, mw.WriteField(k, v))
+	}
+	for _, i := range jars {
+		jar, err := os.ReadFile(filepath.Join("../../../jar-parser", i))
+		assert.NoError(t, err)
+		w, err := mw.CreateFormFile("upload", i)
+		assert.NoError(t, err)
+		_, err = w.Write(jar)
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, mw.Close())
+	req := httptest.NewRequest(http.MethodPost, "/release/clock", body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer abcd")
+	return req
+}
+
+func TestReleasePost(t *testing.T) {
+	r := testRoutes(t)
+	router := r.router()
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, releaseRequest(t, map[string]string{"changelog": "Fixed the clock", "release_channel": "beta"}, "test-fabric.jar", "test-forge.jar"))
+	assert.Equal(t, http.StatusAccepted, rec.Code, rec.Body.String())
+	var accepted releaseAccepted
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&accepted))
+	assert.Len(t, accepted.Builds, 2)
+
+	// each jar keeps its own metadata and shares the release fields
+	builds, err := r.db.ListReleaseBuilds(context.Background(), accepted.ReleaseId)
+	assert.NoError(t, err)
+	assert.Len(t, builds, 2)
+	assert.Equal(t, "test-fabric.jar", builds[0].Filename)
+	assert.Equal(t, []string{"fabric"}, builds[0].Meta.Loaders)
+	assert.Equal(t, "test-forge.jar", builds[1].Filename)
+	assert.Equal(t, []string{"forge"}, builds[1].Meta.Loaders)
+	for _, i := range builds {
+		assert.Equal(t, "Fixed the clock", i.Changelog)
+		assert.Equal(t, "beta", i.Meta.ReleaseChannel)
+	}
+
+	// uploading the same jars again is a conflict
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, releaseRequest(t, nil, "test-quilt.jar", "test-fabric.jar"))
+	assert.Equal(t, http.StatusConflict, rec.Code)
+}
+
+func TestReleasePost_Rejected(t *testing.T) {
+	for _, i := range []struct {
+		name   string
+		values map[string]string
+		jars   []string
+		token  string
+		code   int
+		body   string
+	}{
+		{name: "wrong token", jars: []string{"test-fabric.jar"}, token: "Bearer efgh", code: http.StatusForbidden, body: "403 Forbidden\n"},
+		{name: "duplicate jar", jars: []string{"test-fabric.jar", "test-fabric.jar"}, code: http.StatusBadRequest, body: "Duplicate file: test-fabric.jar\n"},
+		{name: "version number", values: map[string]string{"version_number": "1.0.0"}, jars: []string{"test-fabric.jar", "test-forge.jar"}, code: http.StatusBadRequest, body: "version_number is not supported in releases\n"},
+		{name: "loaders", values: map[string]string{"loaders": "fabric"}, jars: []string{"test-fabric.jar"}, code: http.StatusBadRequest, body: "loaders is not supported in releases\n"},
+		{name: "environment", values: map[string]string{"environment": "client"}, jars: []string{"test-fabric.jar"}, code: http.StatusBadRequest, body: "environment is not supported in releases\n"},
+		{name: "name", values: map[string]string{"name": "Clock 1.0.0"}, jars: []string{"test-fabric.jar"}, code: http.StatusBadRequest, body: "name is not supported in releases\n"},
+		{name: "game versions", values: map[string]string{"game_versions": "1.20.1"}, jars: []string{"test-fabric.jar"}, code: http.StatusBadRequest, body: "game_versions is not supported in releases\n"},
+	} {
+		t.Run(i.name, func(t *testing.T) {
+			r := testRoutes(t)
+			req := releaseRequest(t, i.values, i.jars...)
+			if i.token != "" {
+				req.Header.Set("Authorization", i.token)
+			}
+			rec := httptest.NewRecorder()
+			r.router().ServeHTTP(rec, req)
+			assert.Equal(t, i.code, rec.Code)
+			assert.Equal(t, i.body, rec.Body.String())
+
+			builds, err := r.db.ListBuilds(context.Background(), "clock")
+			assert.NoError(t, err)
+			assert.Empty(t, builds)
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package routes
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/julienschmidt/httprouter"
+	"github.com/mrmelon54/mc-upload-api/database"
+	"log"
+	"net/http"
+	"slices"
+)
+
+// buildPublishPost queues publishing a stored build to platforms which do not
+// have a remote ID yet, the `platform` query limits the retry to one platform
+func (r routeCtx) buildPublishPost(rw http.ResponseWriter, req *http.Request, params httprouter.Params) {
+	slug := params.ByName("slug")
+	project, ok := (*r.projectsYml.Load())[slug]
+	if !ok {
+		http.Error(rw, "404 Not Found", http.StatusNotFound)
+		return
+	}
+	if !checkProjectToken(project, req) {
+		http.Error(rw, "403 Forbidden", http.StatusForbidden)
+		return
+	}
+
+	platformName := req.URL.Query().Get("platform")
+	if platformName != "" && !slices.Contains(project.EnabledPlatforms(), platformName) {
+		http.Error(rw, "Platform is not enabled for this project", http.StatusBadRequest)
+		return
+	}
+
+	build, err := r.db.GetBuild(req.Context(), database.GetBuildParams{
+		Project: slug,
+		Sha512:  params.ByName("sha512"),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(rw, "404 Not Found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Println("Database Error:", err)
+		http.Error(rw, "Database Error", http.StatusInternalServerError)
+		return
+	}
+
+	jobId, err := r.queue.Enqueue(req.Context(), build.ID, platformName)
+	if err != nil {
+		log.Println("Database Error:", err)
+		http.Error(rw, "Database Error", http.StatusInternalServerError)
+		return
+	}
+	writeJobAccepted(rw, jobId, build.ID, build.Sha512)
+}
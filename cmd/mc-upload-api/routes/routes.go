@@ -1,10 +1,13 @@
 package routes
 
 import (
+	"bytes"
 	"github.com/julienschmidt/httprouter"
 	"github.com/mrmelon54/mc-upload-api"
 	"github.com/mrmelon54/mc-upload-api/database"
+	"github.com/mrmelon54/mc-upload-api/publisher"
 	resolveversions "github.com/mrmelon54/mc-upload-api/resolve-versions"
+	"github.com/mrmelon54/mc-upload-api/storage"
 	"github.com/mrmelon54/mc-upload-api/uploader"
 	"net/http"
 	"strings"
@@ -13,24 +16,50 @@ import (
 
 type routeCtx struct {
 	db          *database.Queries
+	configYml   *atomic.Pointer[mc_upload_api.Config]
 	projectsYml *atomic.Pointer[mc_upload_api.ProjectsConfig]
-	buildDir    string
-	mrUpld      uploader.Uploader
-	cfUpld      uploader.Uploader
+	storage     storage.Storage
+	queue       *publisher.Queue
+	uploaders   uploader.Registry
 	mcVersions  *resolveversions.McVersions
 }
 
-func Router(db *database.Queries, projectsYml *atomic.Pointer[mc_upload_api.ProjectsConfig], buildDir string, mrUpld uploader.Uploader, cfUpld uploader.Uploader, mcVersions *resolveversions.McVersions) http.Handler {
-	base := routeCtx{db, projectsYml, buildDir, mrUpld, cfUpld, mcVersions}
+func Router(db *database.Queries, configYml *atomic.Pointer[mc_upload_api.Config], projectsYml *atomic.Pointer[mc_upload_api.ProjectsConfig], store storage.Storage, queue *publisher.Queue, uploaders uploader.Registry, mcVersions *resolveversions.McVersions) http.Handler {
+	base := routeCtx{db, configYml, projectsYml, store, queue, uploaders, mcVersions}
 
 	r := httprouter.New()
 	r.POST("/upload/:slug", base.uploadPost)
+	r.POST("/validate/:slug", base.validatePost)
+	r.POST("/release/:slug", base.releasePost)
 	r.GET("/summary", base.summaryGet)
 	r.GET("/mod/:slug", base.modGet)
 	r.GET("/mod/:slug/versions", base.modVersionsGet)
+	r.GET("/mod/:slug/forge-update.json", base.modForgeUpdateGet)
+	r.POST("/mod/:slug/builds/:sha512/publish", base.buildPublishPost)
+	r.GET("/mod/:slug/download/:sha512", base.modDownloadGet)
+	r.GET("/jobs/:id", base.jobGet)
+	r.GET("/releases/:id", base.releaseGet)
+	r.GET("/v2/project/:slug", base.mrProjectGet)
+	r.GET("/v2/project/:slug/version", base.mrProjectVersionsGet)
+	r.GET("/v2/version_file/:sha512", base.mrVersionFileGet)
 	return r
 }
 
+// baseUrl is the external address of the server without a trailing slash
+func (r routeCtx) baseUrl(req *http.Request) string {
+	if u := r.configYml.Load().PublicUrl; u != "" {
+		return strings.TrimSuffix(u, "/")
+	}
+	scheme := "http"
+	if req.TLS != nil {
+		scheme = "https"
+	}
+	if proto := req.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	return scheme + "://" + req.Host
+}
+
 func getBearer(req *http.Request) (string, bool) {
 	auth := req.Header.Get("Authorization")
 	if !strings.HasPrefix(auth, "Bearer ") {
@@ -38,3 +67,12 @@ func getBearer(req *http.Request) (string, bool) {
 	}
 	return auth[len("Bearer "):], true
 }
+
+// checkProjectToken reports whether the request has the bearer token for the project
+func checkProjectToken(project mc_upload_api.Project, req *http.Request) bool {
+	bearer, ok := getBearer(req)
+	if !ok {
+		return false
+	}
+	return bytes.Compare([]byte(project.Token), []byte(bearer)) == 0
+}
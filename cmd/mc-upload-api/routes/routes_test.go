@@ -0,0 +1,85 @@
+package routes
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha512"
+	"encoding/hex"
+	"github.com/mrmelon54/mc-upload-api"
+	"github.com/mrmelon54/mc-upload-api/database"
+	"github.com/mrmelon54/mc-upload-api/database/types"
+	"github.com/mrmelon54/mc-upload-api/publisher"
+	"github.com/mrmelon54/mc-upload-api/storage"
+	"github.com/mrmelon54/mc-upload-api/uploader"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testRoutes has an in-memory database, local storage and the clock project
+// with the token "abcd"
+func testRoutes(t *testing.T) routeCtx {
+	db, err := mc_upload_api.InitDB("file:" + t.Name() + "?mode=memory&cache=shared")
+	assert.NoError(t, err)
+	store, err := storage.NewLocal(t.TempDir())
+	assert.NoError(t, err)
+	configYml := new(atomic.Pointer[mc_upload_api.Config])
+	configYml.Store(new(mc_upload_api.Config))
+	projectsYml := new(atomic.Pointer[mc_upload_api.ProjectsConfig])
+	projectsYml.Store(&mc_upload_api.ProjectsConfig{"clock": {Token: "abcd"}})
+	return routeCtx{
+		db:          db,
+		configYml:   configYml,
+		projectsYml: projectsYml,
+		storage:     store,
+		queue:       publisher.NewQueue(db, nil, projectsYml),
+		uploaders:   uploader.Registry{},
+		mcVersions:  testMcVersions(),
+	}
+}
+
+func (r routeCtx) router() http.Handler {
+	return Router(r.db, r.configYml, r.projectsYml, r.storage, r.queue, r.uploaders, r.mcVersions)
+}
+
+// createTestBuild stores a jar containing the version number and inserts the
+// build, it returns the sha512 of the jar
+func createTestBuild(t *testing.T, r routeCtx, meta types.BuildMeta, createdAt time.Time) string {
+	jar := []byte("clock " + meta.VersionNumber)
+	hash := sha512.Sum512(jar)
+	sha := hex.EncodeToString(hash[:])
+	assert.NoError(t, r.storage.Put(context.Background(), sha, bytes.NewReader(jar), int64(len(jar))))
+	_, err := r.db.CreateBuild(context.Background(), database.CreateBuildParams{
+		Project:   "clock",
+		Meta:      &meta,
+		Filename:  "clock-" + meta.VersionNumber + ".jar",
+		Sha512:    sha,
+		Changelog: "changes in " + meta.VersionNumber,
+		CreatedAt: createdAt.Unix(),
+		Size:      int64(len(jar)),
+	})
+	assert.NoError(t, err)
+	return sha
+}
+
+// createHiddenBuilds inserts the listed builds 1.0.0 and 1.0.1 followed by a
+// draft, an unlisted and a scheduled build, it returns the sha512 of each version
+func createHiddenBuilds(t *testing.T, r routeCtx) map[string]string {
+	now := time.Now()
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+	build := func(version string) types.BuildMeta {
+		return types.BuildMeta{VersionNumber: version, ReleaseChannel: "release", GameVersions: []string{"1.20.1"}, Loaders: []string{"forge"}}
+	}
+	listed, scheduledBefore, draft, unlisted, scheduled := build("1.0.0"), build("1.0.1"), build("1.1.0"), build("1.2.0"), build("1.3.0")
+	scheduledBefore.ScheduledAt = &past
+	draft.Status = "draft"
+	unlisted.Status = "unlisted"
+	scheduled.ScheduledAt = &future
+	shas := make(map[string]string)
+	for _, i := range []types.BuildMeta{listed, scheduledBefore, draft, unlisted, scheduled} {
+		shas[i.VersionNumber] = createTestBuild(t, r, i, past)
+	}
+	return shas
+}
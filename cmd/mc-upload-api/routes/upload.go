@@ -1,25 +1,48 @@
 package routes
 
 import (
-	"bytes"
+	"context"
 	"crypto/sha512"
+	"database/sql"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"github.com/julienschmidt/httprouter"
+	"github.com/mrmelon54/mc-upload-api"
 	"github.com/mrmelon54/mc-upload-api/database"
 	"github.com/mrmelon54/mc-upload-api/database/types"
 	jarparser "github.com/mrmelon54/mc-upload-api/jar-parser"
+	"github.com/mrmelon54/mc-upload-api/publisher"
 	resolveversions "github.com/mrmelon54/mc-upload-api/resolve-versions"
+	"github.com/mrmelon54/mc-upload-api/storage"
+	"github.com/mrmelon54/mc-upload-api/uploader"
 	"io"
 	"log"
+	"mime/multipart"
 	"net/http"
+	"net/url"
 	"os"
-	"path/filepath"
+	"slices"
+	"time"
 )
 
+// MaxFilesize is the default upload limit for projects without a maxFilesize
 const MaxFilesize = 5 << 20 // 5 MiB
 
+const MaxChangelogSize = 64 << 10 // 64 KiB
+
+// MinUploadRate is the slowest accepted upload speed in bytes per second
+const MinUploadRate = 64 << 10 // 64 KiB/s
+
+// MaxFormValueSize limits each text field other than the changelog
+const MaxFormValueSize = 8 << 10 // 8 KiB
+
+// MaxFormValuesSize limits the combined size of the text fields in an upload
+const MaxFormValuesSize = 1 << 20 // 1 MiB
+
+// additionalFileKinds are the form parts stored alongside the main jar
+var additionalFileKinds = []string{"sources", "javadoc", "dev"}
+
 func (r routeCtx) uploadPost(rw http.ResponseWriter, req *http.Request, params httprouter.Params) {
 	slug := params.ByName("slug")
 	project, ok := (*r.projectsYml.Load())[slug]
@@ -27,41 +50,32 @@ func (r routeCtx) uploadPost(rw http.ResponseWriter, req *http.Request, params h
 		http.Error(rw, "404 Not Found", http.StatusNotFound)
 		return
 	}
-	bearer, ok := getBearer(req)
-	if !ok {
+	if !checkProjectToken(project, req) {
 		http.Error(rw, "403 Forbidden", http.StatusForbidden)
 		return
 	}
-	if bytes.Compare([]byte(project.Token), []byte(bearer)) != 0 {
-		http.Error(rw, "403 Forbidden", http.StatusForbidden)
+
+	form, ok := r.readProjectUpload(rw, req, project, 1)
+	if !ok {
 		return
 	}
-	mpFile, mpFileHeader, err := req.FormFile("upload")
+	defer form.Close()
+
+	modMeta, err := jarparser.JarParser(form.File, form.Size)
 	if err != nil {
-		http.Error(rw, "Invalid file", http.StatusInternalServerError)
-		return
-	}
-	if mpFileHeader.Size > MaxFilesize {
-		http.Error(rw, "File too big", http.StatusRequestEntityTooLarge)
+		log.Println("Failed to parse JAR:", err)
+		http.Error(rw, "Failed to parse JAR", http.StatusInternalServerError)
 		return
 	}
 
-	fileBuffer := new(bytes.Buffer)
-	_, err = io.CopyN(fileBuffer, mpFile, MaxFilesize)
-	if err != nil && !errors.Is(err, io.EOF) {
-		http.Error(rw, "Failed to transfer file", http.StatusInternalServerError)
+	overrides, err := r.applyOverrides(form.Values, project, &modMeta)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
 		return
 	}
-
-	// calculate file hash
-	h512 := sha512.New()
-	h512.Write(fileBuffer.Bytes())
-	h512hex := hex.EncodeToString(h512.Sum(nil))
-
-	modMeta, err := jarparser.JarParser(bytes.NewReader(fileBuffer.Bytes()), int64(fileBuffer.Len()))
+	origin, err := readProvenance(form.Values, project)
 	if err != nil {
-		log.Println("Failed to parse JAR:", err)
-		http.Error(rw, "Failed to parse JAR", http.StatusInternalServerError)
+		http.Error(rw, err.Error(), http.StatusBadRequest)
 		return
 	}
 
@@ -72,7 +86,16 @@ func (r routeCtx) uploadPost(rw http.ResponseWriter, req *http.Request, params h
 		return
 	}
 
-	hashExists, err := r.db.HashExists(req.Context(), h512hex)
+	if overrides.Name == "" {
+		overrides.Name, err = project.RenderVersionName(mc_upload_api.NewVersionNameData(modMeta, gameVersions, form.Filename))
+		if err != nil {
+			log.Println("Failed to render version name:", err)
+			http.Error(rw, "Failed to render version name", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	hashExists, err := r.db.HashExists(req.Context(), form.Sha512)
 	if err != nil {
 		log.Println("Failed to check if hash already exists:", err)
 		http.Error(rw, "Failed to check if hash already exists", http.StatusInternalServerError)
@@ -84,70 +107,331 @@ func (r routeCtx) uploadPost(rw http.ResponseWriter, req *http.Request, params h
 		return
 	}
 
-	lastId, err := r.db.CreateBuild(req.Context(), database.CreateBuildParams{
-		Project: slug,
-		Meta: &types.BuildMeta{
-			VersionNumber:  modMeta.VersionNumber,
-			ReleaseChannel: modMeta.ReleaseChannel,
-			GameVersions:   gameVersions,
-			Loaders:        modMeta.Loaders,
-			Environment:    modMeta.Environment,
-		},
-		Filename: mpFileHeader.Filename,
-		Sha512:   h512hex,
-	})
-	if err != nil {
-		log.Println("Database Error:", err)
-		http.Error(rw, "Database Error", http.StatusInternalServerError)
-		return
-	}
-
-	datCreate, err := os.Create(filepath.Join(r.buildDir, h512hex+".jar"))
-	if err != nil {
+	// files are stored by hash so they can be stored before the build rows, the
+	// transaction then only holds the database lock for the inserts
+	stored := append(form.Uploads(), form.Files...)
+	if err := r.storeUploads(req.Context(), stored); err != nil {
+		r.removeStored(req.Context(), stored)
 		log.Println("Failed file saving:", err)
 		http.Error(rw, "Failed file saving", http.StatusInternalServerError)
 		return
 	}
-	_, err = datCreate.Write(fileBuffer.Bytes())
+
+	var lastId, jobId int64
+	err = r.db.InTx(req.Context(), func(q *database.Queries) error {
+		var err error
+		lastId, err = r.createBuild(req.Context(), q, database.CreateBuildParams{
+			Project:    slug,
+			Meta:       newBuildMeta(modMeta, gameVersions, overrides),
+			Filename:   form.Filename,
+			Sha512:     form.Sha512,
+			Size:       form.Size,
+			Changelog:  form.Changelog,
+			CreatedAt:  time.Now().Unix(),
+			UploadedBy: origin.UploadedBy,
+			GitCommit:  origin.GitCommit,
+			GitRef:     origin.GitRef,
+			CiRunUrl:   origin.CiRunUrl,
+		}, form.Files)
+		if err != nil {
+			return err
+		}
+		jobId, err = publisher.CreateJob(req.Context(), q, lastId, "")
+		return err
+	})
 	if err != nil {
-		log.Println("Failed file saving:", err)
-		http.Error(rw, "Failed file saving", http.StatusInternalServerError)
+		r.removeStored(req.Context(), stored)
+	}
+	if database.IsUniqueViolation(err) {
+		// a concurrent upload of the same jar was committed first
+		http.Error(rw, "This hash is already uploaded", http.StatusOK)
+		return
+	} else if err != nil {
+		log.Println("Failed to create build:", err)
+		http.Error(rw, "Failed to create build", http.StatusInternalServerError)
 		return
 	}
+	r.queue.Wake()
+	writeJobAccepted(rw, jobId, lastId, form.Sha512)
+}
 
-	if project.Modrinth.Enabled() {
-		log.Printf("[Upload] Updating project %s (%s) on Modrinth\n", project.Name, project.Modrinth.Id)
-		mrId, err := r.mrUpld.UploadVersion(project.Modrinth.Id, modMeta, gameVersions, mpFileHeader.Filename, bytes.NewReader(fileBuffer.Bytes()))
-		if err != nil {
-			http.Error(rw, fmt.Errorf("upload modrinth: %w", err).Error(), http.StatusInternalServerError)
-			return
+// storeUploads puts the upload files in the storage
+func (r routeCtx) storeUploads(ctx context.Context, files []*formFile) error {
+	for _, i := range files {
+		if err := i.Store(ctx, r.storage); err != nil {
+			return err
 		}
-		err = r.db.UpdateModrinthFile(req.Context(), database.UpdateModrinthFileParams{
-			ModrinthID: mrId,
-			ID:         lastId,
+	}
+	return nil
+}
+
+// createBuild inserts the build and its additional files, the files must be
+// stored first
+func (r routeCtx) createBuild(ctx context.Context, q *database.Queries, params database.CreateBuildParams, files []*formFile) (int64, error) {
+	buildId, err := q.CreateBuild(ctx, params)
+	if err != nil {
+		return 0, err
+	}
+	for _, i := range files {
+		err = q.CreateBuildFile(ctx, database.CreateBuildFileParams{
+			BuildID:  buildId,
+			Kind:     i.Kind,
+			Filename: i.Filename,
+			Sha512:   i.Sha512,
+			Size:     i.Size,
 		})
 		if err != nil {
-			log.Println("Database Error:", err)
-			http.Error(rw, "Database Error", http.StatusInternalServerError)
-			return
+			return 0, err
 		}
 	}
-	if project.Curseforge.Enabled() {
-		log.Printf("[Upload] Updating project %s (%s) on Curseforge\n", project.Name, project.Curseforge.Id)
-		cfId, err := r.cfUpld.UploadVersion(project.Curseforge.Id, modMeta, gameVersions, mpFileHeader.Filename, bytes.NewReader(fileBuffer.Bytes()))
-		if err != nil {
-			http.Error(rw, fmt.Errorf("upload curseforge: %w", err).Error(), http.StatusInternalServerError)
-			return
+	return buildId, nil
+}
+
+// removeStored deletes files stored by a failed upload unless a committed build
+// references them, files in shared storage are left for other instances
+func (r routeCtx) removeStored(ctx context.Context, files []*formFile) {
+	if storage.Shared(r.storage) {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+	for _, i := range files {
+		_, err := r.db.GetBuildByFileHash(ctx, i.Sha512)
+		if !errors.Is(err, sql.ErrNoRows) {
+			continue
 		}
-		err = r.db.UpdateCurseforgeFile(req.Context(), database.UpdateCurseforgeFileParams{
-			CurseforgeID: cfId,
-			ID:           lastId,
-		})
+		if err := r.storage.Delete(ctx, i.Sha512); err != nil {
+			log.Println("Failed to remove stored file:", err)
+		}
+	}
+}
+
+func newBuildMeta(modMeta jarparser.ModMetadata, gameVersions []string, overrides uploadOverrides) *types.BuildMeta {
+	return &types.BuildMeta{
+		VersionNumber:  modMeta.VersionNumber,
+		ReleaseChannel: modMeta.ReleaseChannel,
+		GameVersions:   gameVersions,
+		Loaders:        modMeta.Loaders,
+		Environment:    modMeta.Environment,
+		Name:           overrides.Name,
+		Featured:       overrides.Featured,
+		Status:         overrides.Status,
+		ScheduledAt:    overrides.ScheduledAt,
+		Overrides:      overrides.Fields,
+	}
+}
+
+// readProjectUpload reads the upload form within the project size limit, an error
+// response is written if the form is invalid
+func (r routeCtx) readProjectUpload(rw http.ResponseWriter, req *http.Request, project mc_upload_api.Project, maxJars int) (*uploadForm, bool) {
+	maxFilesize := int64(MaxFilesize)
+	if project.MaxFilesize > 0 {
+		maxFilesize = project.MaxFilesize
+	}
+
+	// the server timeouts would cut off large uploads on slow connections, the
+	// deadlines allow every file part at the minimum upload rate
+	maxBody := maxFilesize * int64(maxJars+len(additionalFileKinds))
+	timeout := time.Minute + time.Duration(maxBody/MinUploadRate)*time.Second
+	rc := http.NewResponseController(rw)
+	_ = rc.SetReadDeadline(time.Now().Add(timeout))
+	_ = rc.SetWriteDeadline(time.Now().Add(timeout + time.Minute))
+
+	form, err := readUploadForm(req, maxFilesize, maxJars)
+	switch {
+	case err == nil:
+		return form, true
+	case errors.Is(err, errFileTooBig):
+		http.Error(rw, "File too big", http.StatusRequestEntityTooLarge)
+	case errors.Is(err, errTooManyJars):
+		http.Error(rw, "Too many files", http.StatusBadRequest)
+	case errors.Is(err, errChangelogTooBig):
+		http.Error(rw, "Invalid changelog", http.StatusBadRequest)
+	case errors.Is(err, errFormValueTooBig):
+		http.Error(rw, "Form value too big", http.StatusBadRequest)
+	case errors.Is(err, http.ErrMissingFile):
+		http.Error(rw, "Invalid file", http.StatusBadRequest)
+	default:
+		log.Println("Failed to read upload:", err)
+		http.Error(rw, "Failed to transfer file", http.StatusBadRequest)
+	}
+	return nil, false
+}
+
+var (
+	errFileTooBig      = errors.New("file too big")
+	errChangelogTooBig = errors.New("changelog too big")
+	errFormValueTooBig = errors.New("form value too big")
+	errTooManyJars     = errors.New("too many upload parts")
+)
+
+// uploadForm is a streamed multipart upload, the `upload` part and additional
+// files are spooled to temp files and hashed while they are written
+type uploadForm struct {
+	// Values contains the text fields
+	Values url.Values
+
+	// Changelog is read from the `changelog` field or the `changelog_file` part
+	Changelog string
+
+	formFile
+
+	// Jars are the upload parts after the first, only read for releases
+	Jars []*formFile
+
+	// Files are the additional files in the order they were sent
+	Files []*formFile
+}
+
+type formFile struct {
+	// Kind is the form name of an additional file
+	Kind string
+
+	Filename string
+	File     *os.File
+	Size     int64
+	Sha512   string
+}
+
+// readUploadForm reads the form with up to maxJars upload parts
+func readUploadForm(req *http.Request, maxFilesize int64, maxJars int) (*uploadForm, error) {
+	mpr, err := req.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+	form := &uploadForm{Values: make(url.Values)}
+	valuesSize := 0
+	for {
+		part, err := mpr.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			form.Close()
+			return nil, err
+		}
+
+		switch name := part.FormName(); {
+		case name == "upload" && form.File == nil:
+			err = form.spool(part, maxFilesize)
+		case name == "upload":
+			if len(form.Jars)+1 >= maxJars {
+				err = errTooManyJars
+				break
+			}
+			f := new(formFile)
+			form.Jars = append(form.Jars, f)
+			err = f.spool(part, maxFilesize)
+		case slices.Contains(additionalFileKinds, name):
+			if slices.ContainsFunc(form.Files, func(f *formFile) bool { return f.Kind == name }) {
+				err = fmt.Errorf("duplicate %s part", name)
+				break
+			}
+			f := &formFile{Kind: name}
+			form.Files = append(form.Files, f)
+			err = f.spool(part, maxFilesize)
+		case name == "changelog_file":
+			form.Changelog, err = readFormValue(part, MaxChangelogSize, errChangelogTooBig)
+		default:
+			limit, tooBig := MaxFormValueSize, errFormValueTooBig
+			if name == "changelog" {
+				limit, tooBig = MaxChangelogSize, errChangelogTooBig
+			}
+			var value string
+			value, err = readFormValue(part, limit, tooBig)
+			valuesSize += len(value)
+			if valuesSize > MaxFormValuesSize {
+				err = errFormValueTooBig
+			}
+			form.Values.Add(name, value)
+		}
+		_ = part.Close()
 		if err != nil {
-			log.Println("Database Error:", err)
-			http.Error(rw, "Database Error", http.StatusInternalServerError)
-			return
+			form.Close()
+			return nil, err
 		}
 	}
-	http.Error(rw, "OK", http.StatusOK)
+	if form.File == nil {
+		form.Close()
+		return nil, http.ErrMissingFile
+	}
+	if changelog := form.Values.Get("changelog"); changelog != "" {
+		form.Changelog = changelog
+	}
+	return form, nil
+}
+
+func readFormValue(r io.Reader, limit int, tooBig error) (string, error) {
+	value, err := io.ReadAll(io.LimitReader(r, int64(limit)+1))
+	if err != nil {
+		return "", err
+	}
+	if len(value) > limit {
+		return "", tooBig
+	}
+	return string(value), nil
+}
+
+func (f *formFile) spool(part *multipart.Part, maxFilesize int64) error {
+	file, err := os.CreateTemp("", "upload-*.tmp")
+	if err != nil {
+		return err
+	}
+	f.File = file
+	f.Filename = part.FileName()
+
+	h512 := sha512.New()
+	n, err := io.Copy(io.MultiWriter(file, h512), io.LimitReader(part, maxFilesize+1))
+	if err != nil {
+		return err
+	}
+	if n > maxFilesize {
+		return errFileTooBig
+	}
+	f.Size = n
+	f.Sha512 = hex.EncodeToString(h512.Sum(nil))
+	return nil
+}
+
+// Store puts the temp file in the storage
+func (f *formFile) Store(ctx context.Context, s storage.Storage) error {
+	if _, err := f.File.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if err := s.Put(ctx, f.Sha512, f.File, f.Size); err != nil {
+		return fmt.Errorf("failed to store upload: %w", err)
+	}
+	return nil
+}
+
+// Close removes the temp file
+func (f *formFile) Close() {
+	if f.File == nil {
+		return
+	}
+	_ = f.File.Close()
+	_ = os.Remove(f.File.Name())
+}
+
+// VersionFiles returns the additional files for the uploaders
+func (f *uploadForm) VersionFiles() []uploader.File {
+	a := make([]uploader.File, len(f.Files))
+	for i, file := range f.Files {
+		a[i] = uploader.File{Kind: file.Kind, Filename: file.Filename, File: file.File, Size: file.Size}
+	}
+	return a
+}
+
+// Uploads returns the first upload part followed by the other jars
+func (f *uploadForm) Uploads() []*formFile {
+	return append([]*formFile{&f.formFile}, f.Jars...)
+}
+
+// Close removes the temp files
+func (f *uploadForm) Close() {
+	f.formFile.Close()
+	for _, i := range f.Jars {
+		i.Close()
+	}
+	for _, i := range f.Files {
+		i.Close()
+	}
 }
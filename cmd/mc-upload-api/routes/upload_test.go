@@ -0,0 +1,43 @@
+package routes
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func uploadRequest(t *testing.T, values map[string]string) *http.Request {
+	body := new(bytes.Buffer)
+	mw := multipart.NewWriter(body)
+	for k, v := range values {
+		assert.NoError(t, mw.WriteField(k, v))
+	}
+	w, err := mw.CreateFormFile("upload", "clock.jar")
+	assert.NoError(t, err)
+	_, err = w.Write([]byte("jar"))
+	assert.NoError(t, err)
+	assert.NoError(t, mw.Close())
+	req := httptest.NewRequest(http.MethodPost, "/mod/clock/upload", body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func TestReadUploadForm_Values(t *testing.T) {
+	longChangelog := strings.Repeat("a", MaxFormValueSize+1)
+	form, err := readUploadForm(uploadRequest(t, map[string]string{"changelog": longChangelog, "version_number": "1.0.0"}), MaxFilesize, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, longChangelog, form.Changelog)
+	assert.Equal(t, "1.0.0", form.Values.Get("version_number"))
+	assert.Equal(t, "clock.jar", form.Filename)
+	form.Close()
+
+	_, err = readUploadForm(uploadRequest(t, map[string]string{"game_versions": strings.Repeat("1.20.1,", MaxFormValueSize)}), MaxFilesize, 1)
+	assert.ErrorIs(t, err, errFormValueTooBig)
+
+	_, err = readUploadForm(uploadRequest(t, map[string]string{"changelog": strings.Repeat("a", MaxChangelogSize+1)}), MaxFilesize, 1)
+	assert.ErrorIs(t, err, errChangelogTooBig)
+}
@@ -0,0 +1,282 @@
+package routes
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"github.com/julienschmidt/httprouter"
+	"github.com/mrmelon54/mc-upload-api/database"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// The v2 routes serve a read only subset of the Modrinth v2 API so launchers
+// and update checkers can use this server instead of Modrinth
+
+type mrProject struct {
+	Id           string   `json:"id"`
+	Slug         string   `json:"slug"`
+	ProjectType  string   `json:"project_type"`
+	Title        string   `json:"title"`
+	Description  string   `json:"description"`
+	Status       string   `json:"status"`
+	GameVersions []string `json:"game_versions"`
+	Loaders      []string `json:"loaders"`
+	Versions     []string `json:"versions"`
+}
+
+type mrVersion struct {
+	Id            string         `json:"id"`
+	ProjectId     string         `json:"project_id"`
+	Name          string         `json:"name"`
+	VersionNumber string         `json:"version_number"`
+	Changelog     string         `json:"changelog"`
+	Dependencies  []mrDependency `json:"dependencies"`
+	GameVersions  []string       `json:"game_versions"`
+	VersionType   string         `json:"version_type"`
+	Loaders       []string       `json:"loaders"`
+	Featured      bool           `json:"featured"`
+	Status        string         `json:"status"`
+	Downloads     int            `json:"downloads"`
+	Files         []mrFile       `json:"files"`
+}
+
+type mrDependency struct {
+	VersionId      *string `json:"version_id"`
+	ProjectId      *string `json:"project_id"`
+	FileName       *string `json:"file_name"`
+	DependencyType string  `json:"dependency_type"`
+}
+
+type mrFile struct {
+	Hashes   map[string]string `json:"hashes"`
+	Url      string            `json:"url"`
+	Filename string            `json:"filename"`
+	Primary  bool              `json:"primary"`
+	Size     int64             `json:"size"`
+	FileType *string           `json:"file_type"`
+}
+
+type mrError struct {
+	Error       string `json:"error"`
+	Description string `json:"description"`
+}
+
+func writeMrError(rw http.ResponseWriter, code int, name, description string) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(code)
+	_ = json.NewEncoder(rw).Encode(mrError{Error: name, Description: description})
+}
+
+func writeMrJson(rw http.ResponseWriter, v any) {
+	rw.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(rw).Encode(v)
+}
+
+func (r routeCtx) mrProjectGet(rw http.ResponseWriter, req *http.Request, params httprouter.Params) {
+	slug := params.ByName("slug")
+	project, ok := (*r.projectsYml.Load())[slug]
+	if !ok {
+		writeMrError(rw, http.StatusNotFound, "not_found", "the requested route does not exist")
+		return
+	}
+	rows, err := r.db.ListBuilds(req.Context(), slug)
+	if err != nil {
+		log.Println("Database Error:", err)
+		writeMrError(rw, http.StatusInternalServerError, "database_error", "Database Error")
+		return
+	}
+	rows = listedBuilds(project, rows, time.Now())
+	p := mrProject{
+		Id:           slug,
+		Slug:         slug,
+		ProjectType:  "mod",
+		Title:        project.Name,
+		Status:       "approved",
+		GameVersions: []string{},
+		Loaders:      []string{},
+		Versions:     make([]string, 0, len(rows)),
+	}
+	for _, row := range rows {
+		p.Versions = append(p.Versions, strconv.FormatInt(row.ID, 10))
+		p.GameVersions = appendMissing(p.GameVersions, row.Meta.GameVersions...)
+		p.Loaders = appendMissing(p.Loaders, row.Meta.Loaders...)
+	}
+	slices.Sort(p.Loaders)
+	writeMrJson(rw, p)
+}
+
+func (r routeCtx) mrProjectVersionsGet(rw http.ResponseWriter, req *http.Request, params httprouter.Params) {
+	slug := params.ByName("slug")
+	project, ok := (*r.projectsYml.Load())[slug]
+	if !ok {
+		writeMrError(rw, http.StatusNotFound, "not_found", "the requested route does not exist")
+		return
+	}
+	query := req.URL.Query()
+	loaders, err := mrListFilter(query, "loaders")
+	if err != nil {
+		writeMrError(rw, http.StatusBadRequest, "invalid_input", "invalid loaders: "+err.Error())
+		return
+	}
+	gameVersions, err := mrListFilter(query, "game_versions")
+	if err != nil {
+		writeMrError(rw, http.StatusBadRequest, "invalid_input", "invalid game_versions: "+err.Error())
+		return
+	}
+	var featured *bool
+	if v := query.Get("featured"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			writeMrError(rw, http.StatusBadRequest, "invalid_input", "invalid featured: "+v)
+			return
+		}
+		featured = &b
+	}
+
+	rows, err := r.db.ListBuilds(req.Context(), slug)
+	if err != nil {
+		log.Println("Database Error:", err)
+		writeMrError(rw, http.StatusInternalServerError, "database_error", "Database Error")
+		return
+	}
+	fileRows, err := r.db.ListBuildFiles(req.Context(), slug)
+	if err != nil {
+		log.Println("Database Error:", err)
+		writeMrError(rw, http.StatusInternalServerError, "database_error", "Database Error")
+		return
+	}
+	files := make(map[int64][]database.BuildFile)
+	for _, i := range fileRows {
+		files[i.BuildID] = append(files[i.BuildID], i)
+	}
+
+	// newest versions are listed first like Modrinth
+	rows = listedBuilds(project, rows, time.Now())
+	versions := make([]mrVersion, 0, len(rows))
+	for _, row := range slices.Backward(rows) {
+		if len(loaders) > 0 && !containsAny(row.Meta.Loaders, loaders) {
+			continue
+		}
+		if len(gameVersions) > 0 && !containsAny(row.Meta.GameVersions, gameVersions) {
+			continue
+		}
+		v := r.newMrVersion(req, listedBuild(slug, row), files[row.ID])
+		if featured != nil && v.Featured != *featured {
+			continue
+		}
+		versions = append(versions, v)
+	}
+	writeMrJson(rw, versions)
+}
+
+func (r routeCtx) mrVersionFileGet(rw http.ResponseWriter, req *http.Request, params httprouter.Params) {
+	if algorithm := req.URL.Query().Get("algorithm"); algorithm != "" && algorithm != "sha512" {
+		writeMrError(rw, http.StatusBadRequest, "invalid_input", "only the sha512 algorithm is supported")
+		return
+	}
+	build, err := r.db.GetBuildByFileHash(req.Context(), params.ByName("sha512"))
+	if errors.Is(err, sql.ErrNoRows) {
+		writeMrError(rw, http.StatusNotFound, "not_found", "the requested route does not exist")
+		return
+	} else if err != nil {
+		log.Println("Database Error:", err)
+		writeMrError(rw, http.StatusInternalServerError, "database_error", "Database Error")
+		return
+	}
+	project, ok := (*r.projectsYml.Load())[build.Project]
+	if !ok || !buildListed(project, build.Meta, build.CreatedAt, time.Now()) {
+		writeMrError(rw, http.StatusNotFound, "not_found", "the requested route does not exist")
+		return
+	}
+	buildFiles, err := r.db.ListFilesForBuild(req.Context(), build.ID)
+	if err != nil {
+		log.Println("Database Error:", err)
+		writeMrError(rw, http.StatusInternalServerError, "database_error", "Database Error")
+		return
+	}
+	writeMrJson(rw, r.newMrVersion(req, build, buildFiles))
+}
+
+// newMrVersion converts a build to a Modrinth version, dependencies are not
+// stored so the list is always empty
+func (r routeCtx) newMrVersion(req *http.Request, build database.Build, buildFiles []database.BuildFile) mrVersion {
+	v := mrVersion{
+		Id:            strconv.FormatInt(build.ID, 10),
+		ProjectId:     build.Project,
+		Name:          build.Meta.Name,
+		VersionNumber: build.Meta.VersionNumber,
+		Changelog:     build.Changelog,
+		Dependencies:  []mrDependency{},
+		GameVersions:  build.Meta.GameVersions,
+		VersionType:   build.Meta.ReleaseChannel,
+		Loaders:       build.Meta.Loaders,
+		Status:        build.Meta.Status,
+		Files:         make([]mrFile, 0, len(buildFiles)+1),
+	}
+	if v.Name == "" {
+		v.Name = build.Meta.VersionNumber
+	}
+	if v.GameVersions == nil {
+		v.GameVersions = []string{}
+	}
+	if v.Loaders == nil {
+		v.Loaders = []string{}
+	}
+	if build.Meta.Featured != nil {
+		v.Featured = *build.Meta.Featured
+	}
+	if v.Status == "" {
+		v.Status = "listed"
+	}
+
+	v.Files = append(v.Files, r.newMrFile(req, build.Project, build.Filename, build.Sha512, build.Size, true))
+	for _, i := range buildFiles {
+		v.Files = append(v.Files, r.newMrFile(req, build.Project, i.Filename, i.Sha512, i.Size, false))
+	}
+	return v
+}
+
+func (r routeCtx) newMrFile(req *http.Request, slug, filename, sha512 string, size int64, primary bool) mrFile {
+	return mrFile{
+		Hashes:   map[string]string{"sha512": sha512},
+		Url:      r.baseUrl(req) + "/mod/" + url.PathEscape(slug) + "/download/" + sha512,
+		Filename: filename,
+		Primary:  primary,
+		Size:     size,
+	}
+}
+
+// mrListFilter reads a filter which is a JSON array like Modrinth or a comma
+// separated list
+func mrListFilter(query url.Values, key string) ([]string, error) {
+	v := query.Get(key)
+	if v == "" {
+		return nil, nil
+	}
+	if v[0] != '[' {
+		return formList(query, key), nil
+	}
+	var a []string
+	if err := json.Unmarshal([]byte(v), &a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func containsAny(a, b []string) bool {
+	return slices.ContainsFunc(a, func(s string) bool { return slices.Contains(b, s) })
+}
+
+func appendMissing(a []string, values ...string) []string {
+	for _, i := range values {
+		if !slices.Contains(a, i) {
+			a = append(a, i)
+		}
+	}
+	return a
+}
@@ -0,0 +1,64 @@
+package routes
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMrProjectGet_Hidden(t *testing.T) {
+	r := testRoutes(t)
+	createHiddenBuilds(t, r)
+
+	rec := httptest.NewRecorder()
+	r.router().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v2/project/clock", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var project mrProject
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&project))
+	assert.Equal(t, []string{"1", "2"}, project.Versions)
+}
+
+func TestMrProjectVersionsGet_Hidden(t *testing.T) {
+	r := testRoutes(t)
+	createHiddenBuilds(t, r)
+
+	rec := httptest.NewRecorder()
+	r.router().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v2/project/clock/version", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var versions []mrVersion
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&versions))
+	var numbers []string
+	for _, i := range versions {
+		numbers = append(numbers, i.VersionNumber)
+		assert.Equal(t, "listed", i.Status)
+	}
+	assert.Equal(t, []string{"1.0.1", "1.0.0"}, numbers)
+}
+
+func TestMrVersionFileGet_Hidden(t *testing.T) {
+	r := testRoutes(t)
+	shas := createHiddenBuilds(t, r)
+	for _, i := range []struct {
+		version string
+		code    int
+	}{
+		{version: "1.0.0", code: http.StatusOK},
+		{version: "1.0.1", code: http.StatusOK},
+		{version: "1.1.0", code: http.StatusNotFound},
+		{version: "1.2.0", code: http.StatusNotFound},
+		{version: "1.3.0", code: http.StatusNotFound},
+	} {
+		t.Run(i.version, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			r.router().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v2/version_file/"+shas[i.version], nil))
+			assert.Equal(t, i.code, rec.Code)
+			if i.code == http.StatusOK {
+				var version mrVersion
+				assert.NoError(t, json.NewDecoder(rec.Body).Decode(&version))
+				assert.Equal(t, i.version, version.VersionNumber)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/julienschmidt/httprouter"
+	"github.com/mrmelon54/mc-upload-api"
+	"github.com/mrmelon54/mc-upload-api/database/types"
+	jarparser "github.com/mrmelon54/mc-upload-api/jar-parser"
+	resolveversions "github.com/mrmelon54/mc-upload-api/resolve-versions"
+	"github.com/mrmelon54/mc-upload-api/uploader"
+	"log"
+	"net/http"
+)
+
+type validateResult struct {
+	Valid           bool                    `json:"valid"`
+	Sha512          string                  `json:"sha512"`
+	AlreadyUploaded bool                    `json:"already_uploaded"`
+	Error           string                  `json:"error,omitempty"`
+	Meta            *validateMeta           `json:"meta,omitempty"`
+	Platforms       map[string]platformPlan `json:"platforms,omitempty"`
+}
+
+type validateMeta struct {
+	types.BuildMeta
+	GameVersionRanges []string               `json:"game_version_ranges"`
+	Dependencies      []jarparser.Dependency `json:"dependencies"`
+}
+
+type platformPlan struct {
+	Data     any      `json:"data,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// validatePost reports what an upload would publish to each platform without
+// creating a build or contacting the platforms beyond looking up IDs
+func (r routeCtx) validatePost(rw http.ResponseWriter, req *http.Request, params httprouter.Params) {
+	slug := params.ByName("slug")
+	project, ok := (*r.projectsYml.Load())[slug]
+	if !ok {
+		http.Error(rw, "404 Not Found", http.StatusNotFound)
+		return
+	}
+	if !checkProjectToken(project, req) {
+		http.Error(rw, "403 Forbidden", http.StatusForbidden)
+		return
+	}
+
+	form, ok := r.readProjectUpload(rw, req, project, 1)
+	if !ok {
+		return
+	}
+	defer form.Close()
+
+	hashExists, err := r.db.HashExists(req.Context(), form.Sha512)
+	if err != nil {
+		log.Println("Failed to check if hash already exists:", err)
+		http.Error(rw, "Failed to check if hash already exists", http.StatusInternalServerError)
+		return
+	}
+
+	result := r.validateUpload(req.Context(), project, form)
+	result.AlreadyUploaded = hashExists == 1
+	rw.Header().Set("Content-Type", "application/json")
+	if !result.Valid {
+		rw.WriteHeader(http.StatusUnprocessableEntity)
+	}
+	_ = json.NewEncoder(rw).Encode(result)
+}
+
+func (r routeCtx) validateUpload(ctx context.Context, project mc_upload_api.Project, form *uploadForm) validateResult {
+	result := validateResult{Sha512: form.Sha512}
+
+	modMeta, err := jarparser.JarParser(form.File, form.Size)
+	if err != nil {
+		result.Error = "failed to parse JAR: " + err.Error()
+		return result
+	}
+	overrides, err := r.applyOverrides(form.Values, project, &modMeta)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	origin, err := readProvenance(form.Values, project)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	gameVersions, err := resolveversions.ResolveGameVersions(modMeta.GameVersions, r.mcVersions)
+	if err != nil {
+		result.Error = "failed to resolve game versions: " + err.Error()
+		return result
+	}
+	if overrides.Name == "" {
+		overrides.Name, err = project.RenderVersionName(mc_upload_api.NewVersionNameData(modMeta, gameVersions, form.Filename))
+		if err != nil {
+			result.Error = "failed to render version name: " + err.Error()
+			return result
+		}
+	}
+
+	result.Meta = &validateMeta{
+		BuildMeta:         *newBuildMeta(modMeta, gameVersions, overrides),
+		GameVersionRanges: make([]string, 0, len(modMeta.GameVersions)),
+		Dependencies:      modMeta.Dependencies,
+	}
+	for _, i := range modMeta.GameVersions {
+		result.Meta.GameVersionRanges = append(result.Meta.GameVersionRanges, i.String())
+	}
+	switch {
+	case len(modMeta.Loaders) == 0:
+		result.Error = "no loaders found"
+		return result
+	case len(gameVersions) == 0:
+		result.Error = "no game versions found"
+		return result
+	}
+
+	version := uploader.Version{
+		Meta:         modMeta,
+		GameVersions: gameVersions,
+		Name:         overrides.Name,
+		Changelog:    form.Changelog,
+		GitCommit:    origin.GitCommit,
+		Featured:     overrides.Featured,
+		Status:       overrides.Status,
+		Filename:     form.Filename,
+		File:         form.File,
+		Size:         form.Size,
+		Files:        form.VersionFiles(),
+	}
+	if overrides.ScheduledAt != nil {
+		version.ScheduledAt = *overrides.ScheduledAt
+	}
+	result.Valid = true
+	result.Platforms = make(map[string]platformPlan)
+	for _, platformName := range project.EnabledPlatforms() {
+		upld, ok := r.uploaders[platformName]
+		if !ok {
+			result.Valid = false
+			result.Platforms[platformName] = platformPlan{Error: "platform is not configured"}
+			continue
+		}
+		plan, err := upld.ValidateVersion(ctx, project.Platforms[platformName].Target(), version)
+		if err != nil {
+			result.Valid = false
+			result.Platforms[platformName] = platformPlan{Error: err.Error()}
+			continue
+		}
+		result.Platforms[platformName] = platformPlan{Data: plan.Data, Warnings: plan.Warnings}
+	}
+	return result
+}
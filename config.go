@@ -1,9 +1,74 @@
 package mc_upload_api
 
-import "github.com/mrmelon54/mc-upload-api/uploader"
+import (
+	"fmt"
+	"github.com/mrmelon54/mc-upload-api/storage"
+	"gopkg.in/yaml.v3"
+	"slices"
+	"strings"
+)
 
 type Config struct {
-	Listen     string                    `yaml:"listen"`
-	Modrinth   uploader.ModrinthConfig   `yaml:"modrinth"`
-	Curseforge uploader.CurseforgeConfig `yaml:"curseforge"`
+	Listen string `yaml:"listen"`
+
+	// PublicUrl is the external address used in download links, the request host
+	// is used when empty
+	PublicUrl string `yaml:"publicUrl"`
+
+	// PublishWorkers is the number of background publish workers, defaults to 2
+	PublishWorkers int `yaml:"publishWorkers"`
+
+	// Platforms are decoded by the uploader registry
+	Platforms map[string]yaml.Node `yaml:"platforms"`
+
+	// Storage keeps the uploaded jars, defaults to the builds directory next to the config
+	Storage storage.Config `yaml:"storage"`
+
+	// ModrinthCdn redirects downloads to Modrinth when the build was published there
+	ModrinthCdn ModrinthCdn `yaml:"modrinthCdn"`
+}
+
+type ModrinthCdn struct {
+	// Platform is the name of a modrinth platform, downloads are not redirected when empty
+	Platform string `yaml:"platform"`
+
+	// Url defaults to https://cdn.modrinth.com
+	Url string `yaml:"url"`
+}
+
+func (m ModrinthCdn) BaseUrl() string {
+	if m.Url == "" {
+		return "https://cdn.modrinth.com"
+	}
+	return strings.TrimSuffix(m.Url, "/")
+}
+
+func (c *Config) UnmarshalYAML(value *yaml.Node) error {
+	if err := rejectLegacyKeys(value, "modrinth", "curseforge", "github", "hangar"); err != nil {
+		return err
+	}
+	type rawConfig Config
+	return value.Decode((*rawConfig)(c))
+}
+
+// rejectLegacyKeys fails on the platform keys used before the platforms map, an
+// old config would otherwise load without any platforms and publish nowhere
+func rejectLegacyKeys(value *yaml.Node, keys ...string) error {
+	if value.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		key := value.Content[i]
+		if slices.Contains(keys, key.Value) {
+			return fmt.Errorf("%s on line %d must be moved under platforms", key.Value, key.Line)
+		}
+	}
+	return nil
+}
+
+func (c Config) Workers() int {
+	if c.PublishWorkers < 1 {
+		return 2
+	}
+	return c.PublishWorkers
 }
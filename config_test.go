@@ -0,0 +1,21 @@
+package mc_upload_api
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+	"testing"
+)
+
+func TestConfig_LegacyPlatforms(t *testing.T) {
+	var c Config
+	assert.NoError(t, yaml.Unmarshal([]byte("listen: :8080\nplatforms:\n  modrinth:\n    token: abcd\n"), &c))
+	assert.Equal(t, ":8080", c.Listen)
+	assert.Contains(t, c.Platforms, "modrinth")
+
+	err := yaml.Unmarshal([]byte("listen: :8080\ncurseforge:\n  token: abcd\n"), &c)
+	assert.EqualError(t, err, "curseforge on line 2 must be moved under platforms")
+
+	var projects ProjectsConfig
+	err = yaml.Unmarshal([]byte("clock:\n  name: Clock\n  modrinth:\n    id: abcd\n"), &projects)
+	assert.EqualError(t, err, "modrinth on line 3 must be moved under platforms")
+}
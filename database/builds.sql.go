@@ -12,15 +12,24 @@ import (
 )
 
 const createBuild = `-- name: CreateBuild :execlastid
-INSERT INTO builds (project, meta, filename, sha512, modrinth_id, curseforge_id)
-VALUES (?, ?, ?, ?, "", "")
+INSERT INTO builds (project, meta, filename, sha512, changelog, release_id, created_at, uploaded_by, git_commit, git_ref,
+                    ci_run_url, size)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 `
 
 type CreateBuildParams struct {
-	Project  string           `json:"project"`
-	Meta     *types.BuildMeta `json:"meta"`
-	Filename string           `json:"filename"`
-	Sha512   string           `json:"sha512"`
+	Project    string           `json:"project"`
+	Meta       *types.BuildMeta `json:"meta"`
+	Filename   string           `json:"filename"`
+	Sha512     string           `json:"sha512"`
+	Changelog  string           `json:"changelog"`
+	ReleaseID  int64            `json:"release_id"`
+	CreatedAt  int64            `json:"created_at"`
+	UploadedBy string           `json:"uploaded_by"`
+	GitCommit  string           `json:"git_commit"`
+	GitRef     string           `json:"git_ref"`
+	CiRunUrl   string           `json:"ci_run_url"`
+	Size       int64            `json:"size"`
 }
 
 func (q *Queries) CreateBuild(ctx context.Context, arg CreateBuildParams) (int64, error) {
@@ -29,6 +38,14 @@ func (q *Queries) CreateBuild(ctx context.Context, arg CreateBuildParams) (int64
 		arg.Meta,
 		arg.Filename,
 		arg.Sha512,
+		arg.Changelog,
+		arg.ReleaseID,
+		arg.CreatedAt,
+		arg.UploadedBy,
+		arg.GitCommit,
+		arg.GitRef,
+		arg.CiRunUrl,
+		arg.Size,
 	)
 	if err != nil {
 		return 0, err
@@ -36,6 +53,107 @@ func (q *Queries) CreateBuild(ctx context.Context, arg CreateBuildParams) (int64
 	return result.LastInsertId()
 }
 
+const deleteBuild = `-- name: DeleteBuild :exec
+DELETE
+FROM builds
+WHERE id = ?
+`
+
+func (q *Queries) DeleteBuild(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, deleteBuild, id)
+	return err
+}
+
+const getBuild = `-- name: GetBuild :one
+SELECT id, project, meta, filename, sha512, changelog, release_id, created_at, uploaded_by, git_commit, git_ref, ci_run_url, size
+FROM builds
+WHERE project = ?
+  AND sha512 = ?
+`
+
+type GetBuildParams struct {
+	Project string `json:"project"`
+	Sha512  string `json:"sha512"`
+}
+
+func (q *Queries) GetBuild(ctx context.Context, arg GetBuildParams) (Build, error) {
+	row := q.db.QueryRowContext(ctx, getBuild, arg.Project, arg.Sha512)
+	var i Build
+	err := row.Scan(
+		&i.ID,
+		&i.Project,
+		&i.Meta,
+		&i.Filename,
+		&i.Sha512,
+		&i.Changelog,
+		&i.ReleaseID,
+		&i.CreatedAt,
+		&i.UploadedBy,
+		&i.GitCommit,
+		&i.GitRef,
+		&i.CiRunUrl,
+		&i.Size,
+	)
+	return i, err
+}
+
+const getBuildByID = `-- name: GetBuildByID :one
+SELECT id, project, meta, filename, sha512, changelog, release_id, created_at, uploaded_by, git_commit, git_ref, ci_run_url, size
+FROM builds
+WHERE id = ?
+`
+
+func (q *Queries) GetBuildByID(ctx context.Context, id int64) (Build, error) {
+	row := q.db.QueryRowContext(ctx, getBuildByID, id)
+	var i Build
+	err := row.Scan(
+		&i.ID,
+		&i.Project,
+		&i.Meta,
+		&i.Filename,
+		&i.Sha512,
+		&i.Changelog,
+		&i.ReleaseID,
+		&i.CreatedAt,
+		&i.UploadedBy,
+		&i.GitCommit,
+		&i.GitRef,
+		&i.CiRunUrl,
+		&i.Size,
+	)
+	return i, err
+}
+
+const getBuildByFileHash = `-- name: GetBuildByFileHash :one
+SELECT id, project, meta, filename, sha512, changelog, release_id, created_at, uploaded_by, git_commit, git_ref, ci_run_url, size
+FROM builds
+WHERE sha512 = ?1
+   OR id IN (SELECT build_id FROM build_files WHERE build_files.sha512 = ?1)
+ORDER BY id
+LIMIT 1
+`
+
+func (q *Queries) GetBuildByFileHash(ctx context.Context, sha512 string) (Build, error) {
+	row := q.db.QueryRowContext(ctx, getBuildByFileHash, sha512)
+	var i Build
+	err := row.Scan(
+		&i.ID,
+		&i.Project,
+		&i.Meta,
+		&i.Filename,
+		&i.Sha512,
+		&i.Changelog,
+		&i.ReleaseID,
+		&i.CreatedAt,
+		&i.UploadedBy,
+		&i.GitCommit,
+		&i.GitRef,
+		&i.CiRunUrl,
+		&i.Size,
+	)
+	return i, err
+}
+
 const hashExists = `-- name: HashExists :one
 SELECT EXISTS(SELECT 1 FROM builds WHERE sha512 = ?)
 `
@@ -48,18 +166,25 @@ func (q *Queries) HashExists(ctx context.Context, sha512 string) (int64, error)
 }
 
 const listBuilds = `-- name: ListBuilds :many
-SELECT meta, filename, sha512, modrinth_id, curseforge_id
+SELECT id, meta, filename, sha512, changelog, release_id, created_at, uploaded_by, git_commit, git_ref, ci_run_url, size
 FROM builds
 WHERE project = ?
 ORDER BY id
 `
 
 type ListBuildsRow struct {
-	Meta         *types.BuildMeta `json:"meta"`
-	Filename     string           `json:"filename"`
-	Sha512       string           `json:"sha512"`
-	ModrinthID   string           `json:"modrinth_id"`
-	CurseforgeID string           `json:"curseforge_id"`
+	ID         int64            `json:"id"`
+	Meta       *types.BuildMeta `json:"meta"`
+	Filename   string           `json:"filename"`
+	Sha512     string           `json:"sha512"`
+	Changelog  string           `json:"changelog"`
+	ReleaseID  int64            `json:"release_id"`
+	CreatedAt  int64            `json:"created_at"`
+	UploadedBy string           `json:"uploaded_by"`
+	GitCommit  string           `json:"git_commit"`
+	GitRef     string           `json:"git_ref"`
+	CiRunUrl   string           `json:"ci_run_url"`
+	Size       int64            `json:"size"`
 }
 
 func (q *Queries) ListBuilds(ctx context.Context, project string) ([]ListBuildsRow, error) {
@@ -72,11 +197,18 @@ func (q *Queries) ListBuilds(ctx context.Context, project string) ([]ListBuildsR
 	for rows.Next() {
 		var i ListBuildsRow
 		if err := rows.Scan(
+			&i.ID,
 			&i.Meta,
 			&i.Filename,
 			&i.Sha512,
-			&i.ModrinthID,
-			&i.CurseforgeID,
+			&i.Changelog,
+			&i.ReleaseID,
+			&i.CreatedAt,
+			&i.UploadedBy,
+			&i.GitCommit,
+			&i.GitRef,
+			&i.CiRunUrl,
+			&i.Size,
 		); err != nil {
 			return nil, err
 		}
@@ -91,34 +223,59 @@ func (q *Queries) ListBuilds(ctx context.Context, project string) ([]ListBuildsR
 	return items, nil
 }
 
-const updateCurseforgeFile = `-- name: UpdateCurseforgeFile :exec
-UPDATE builds
-SET curseforge_id = ?
-WHERE id = ?
+const listStoredBuilds = `-- name: ListStoredBuilds :many
+SELECT id, project, sha512, size
+FROM builds
+ORDER BY id
 `
 
-type UpdateCurseforgeFileParams struct {
-	CurseforgeID string `json:"curseforge_id"`
-	ID           int64  `json:"id"`
+type ListStoredBuildsRow struct {
+	ID      int64  `json:"id"`
+	Project string `json:"project"`
+	Sha512  string `json:"sha512"`
+	Size    int64  `json:"size"`
 }
 
-func (q *Queries) UpdateCurseforgeFile(ctx context.Context, arg UpdateCurseforgeFileParams) error {
-	_, err := q.db.ExecContext(ctx, updateCurseforgeFile, arg.CurseforgeID, arg.ID)
-	return err
+func (q *Queries) ListStoredBuilds(ctx context.Context) ([]ListStoredBuildsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listStoredBuilds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListStoredBuildsRow
+	for rows.Next() {
+		var i ListStoredBuildsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Project,
+			&i.Sha512,
+			&i.Size,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-const updateModrinthFile = `-- name: UpdateModrinthFile :exec
+const setBuildSize = `-- name: SetBuildSize :exec
 UPDATE builds
-SET modrinth_id = ?
+SET size = ?
 WHERE id = ?
 `
 
-type UpdateModrinthFileParams struct {
-	ModrinthID string `json:"modrinth_id"`
-	ID         int64  `json:"id"`
+type SetBuildSizeParams struct {
+	Size int64 `json:"size"`
+	ID   int64 `json:"id"`
 }
 
-func (q *Queries) UpdateModrinthFile(ctx context.Context, arg UpdateModrinthFileParams) error {
-	_, err := q.db.ExecContext(ctx, updateModrinthFile, arg.ModrinthID, arg.ID)
+func (q *Queries) SetBuildSize(ctx context.Context, arg SetBuildSizeParams) error {
+	_, err := q.db.ExecContext(ctx, setBuildSize, arg.Size, arg.ID)
 	return err
 }
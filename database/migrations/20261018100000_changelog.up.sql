@@ -0,0 +1,2 @@
+ALTER TABLE builds
+    ADD COLUMN changelog TEXT NOT NULL DEFAULT '';
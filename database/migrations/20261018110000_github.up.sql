@@ -0,0 +1,2 @@
+ALTER TABLE builds
+    ADD COLUMN github_id TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+ALTER TABLE builds
+    ADD COLUMN hangar_id TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,16 @@
+ALTER TABLE builds
+    ADD COLUMN modrinth_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE builds
+    ADD COLUMN curseforge_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE builds
+    ADD COLUMN github_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE builds
+    ADD COLUMN hangar_id TEXT NOT NULL DEFAULT '';
+
+UPDATE builds
+SET modrinth_id   = COALESCE((SELECT remote_id FROM build_platforms WHERE build_id = builds.id AND platform = 'modrinth'), ''),
+    curseforge_id = COALESCE((SELECT remote_id FROM build_platforms WHERE build_id = builds.id AND platform = 'curseforge'), ''),
+    github_id     = COALESCE((SELECT remote_id FROM build_platforms WHERE build_id = builds.id AND platform = 'github'), ''),
+    hangar_id     = COALESCE((SELECT remote_id FROM build_platforms WHERE build_id = builds.id AND platform = 'hangar'), '');
+
+DROP TABLE IF EXISTS build_platforms;
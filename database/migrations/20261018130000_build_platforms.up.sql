@@ -0,0 +1,36 @@
+CREATE TABLE build_platforms
+(
+    build_id  INTEGER NOT NULL REFERENCES builds (id),
+    platform  TEXT    NOT NULL,
+    remote_id TEXT    NOT NULL,
+    PRIMARY KEY (build_id, platform)
+);
+
+INSERT INTO build_platforms (build_id, platform, remote_id)
+SELECT id, 'modrinth', modrinth_id
+FROM builds
+WHERE modrinth_id != '';
+
+INSERT INTO build_platforms (build_id, platform, remote_id)
+SELECT id, 'curseforge', curseforge_id
+FROM builds
+WHERE curseforge_id != '';
+
+INSERT INTO build_platforms (build_id, platform, remote_id)
+SELECT id, 'github', github_id
+FROM builds
+WHERE github_id != '';
+
+INSERT INTO build_platforms (build_id, platform, remote_id)
+SELECT id, 'hangar', hangar_id
+FROM builds
+WHERE hangar_id != '';
+
+ALTER TABLE builds
+    DROP COLUMN modrinth_id;
+ALTER TABLE builds
+    DROP COLUMN curseforge_id;
+ALTER TABLE builds
+    DROP COLUMN github_id;
+ALTER TABLE builds
+    DROP COLUMN hangar_id;
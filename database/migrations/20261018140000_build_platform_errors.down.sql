@@ -0,0 +1,2 @@
+ALTER TABLE build_platforms
+    DROP COLUMN error;
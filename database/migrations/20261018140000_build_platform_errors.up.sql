@@ -0,0 +1,2 @@
+ALTER TABLE build_platforms
+    ADD COLUMN error TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS jobs_status_next_run;
+DROP TABLE IF EXISTS jobs;
@@ -0,0 +1,14 @@
+CREATE TABLE jobs
+(
+    id         INTEGER UNIQUE PRIMARY KEY AUTOINCREMENT,
+    build_id   INTEGER NOT NULL REFERENCES builds (id),
+    platform   TEXT    NOT NULL,
+    status     TEXT    NOT NULL,
+    attempts   INTEGER NOT NULL,
+    next_run   INTEGER NOT NULL,
+    last_error TEXT    NOT NULL,
+    created_at INTEGER NOT NULL,
+    updated_at INTEGER NOT NULL
+);
+
+CREATE INDEX jobs_status_next_run ON jobs (status, next_run);
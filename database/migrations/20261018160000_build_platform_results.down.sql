@@ -0,0 +1,4 @@
+ALTER TABLE build_platforms
+    DROP COLUMN url;
+ALTER TABLE build_platforms
+    DROP COLUMN warnings;
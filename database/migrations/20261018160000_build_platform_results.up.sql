@@ -0,0 +1,4 @@
+ALTER TABLE build_platforms
+    ADD COLUMN url TEXT NOT NULL DEFAULT '';
+ALTER TABLE build_platforms
+    ADD COLUMN warnings TEXT NOT NULL DEFAULT '[]';
@@ -0,0 +1,9 @@
+CREATE TABLE build_files
+(
+    build_id INTEGER NOT NULL REFERENCES builds (id),
+    kind     TEXT    NOT NULL,
+    filename TEXT    NOT NULL,
+    sha512   TEXT    NOT NULL,
+    size     INTEGER NOT NULL,
+    PRIMARY KEY (build_id, kind)
+);
@@ -0,0 +1,4 @@
+ALTER TABLE builds
+    DROP COLUMN release_id;
+
+DROP TABLE IF EXISTS releases;
@@ -0,0 +1,9 @@
+CREATE TABLE releases
+(
+    id         INTEGER UNIQUE PRIMARY KEY AUTOINCREMENT,
+    project    TEXT    NOT NULL,
+    created_at INTEGER NOT NULL
+);
+
+ALTER TABLE builds
+    ADD COLUMN release_id INTEGER NOT NULL DEFAULT 0;
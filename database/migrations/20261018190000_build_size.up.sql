@@ -0,0 +1,2 @@
+ALTER TABLE builds
+    ADD COLUMN size INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS builds_sha512;
@@ -0,0 +1 @@
+CREATE UNIQUE INDEX builds_sha512 ON builds (sha512);
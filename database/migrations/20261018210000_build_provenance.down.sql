@@ -0,0 +1,10 @@
+ALTER TABLE builds
+    DROP COLUMN ci_run_url;
+ALTER TABLE builds
+    DROP COLUMN git_ref;
+ALTER TABLE builds
+    DROP COLUMN git_commit;
+ALTER TABLE builds
+    DROP COLUMN uploaded_by;
+ALTER TABLE builds
+    DROP COLUMN created_at;
@@ -0,0 +1,10 @@
+ALTER TABLE builds
+    ADD COLUMN created_at INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE builds
+    ADD COLUMN uploaded_by TEXT NOT NULL DEFAULT '';
+ALTER TABLE builds
+    ADD COLUMN git_commit TEXT NOT NULL DEFAULT '';
+ALTER TABLE builds
+    ADD COLUMN git_ref TEXT NOT NULL DEFAULT '';
+ALTER TABLE builds
+    ADD COLUMN ci_run_url TEXT NOT NULL DEFAULT '';
@@ -9,11 +9,52 @@ import (
 )
 
 type Build struct {
-	ID           int64            `json:"id"`
-	Project      string           `json:"project"`
-	Meta         *types.BuildMeta `json:"meta"`
-	Filename     string           `json:"filename"`
-	Sha512       string           `json:"sha512"`
-	ModrinthID   string           `json:"modrinth_id"`
-	CurseforgeID string           `json:"curseforge_id"`
+	ID         int64            `json:"id"`
+	Project    string           `json:"project"`
+	Meta       *types.BuildMeta `json:"meta"`
+	Filename   string           `json:"filename"`
+	Sha512     string           `json:"sha512"`
+	Changelog  string           `json:"changelog"`
+	ReleaseID  int64            `json:"release_id"`
+	CreatedAt  int64            `json:"created_at"`
+	UploadedBy string           `json:"uploaded_by"`
+	GitCommit  string           `json:"git_commit"`
+	GitRef     string           `json:"git_ref"`
+	CiRunUrl   string           `json:"ci_run_url"`
+	Size       int64            `json:"size"`
+}
+
+type BuildFile struct {
+	BuildID  int64  `json:"build_id"`
+	Kind     string `json:"kind"`
+	Filename string `json:"filename"`
+	Sha512   string `json:"sha512"`
+	Size     int64  `json:"size"`
+}
+
+type BuildPlatform struct {
+	BuildID  int64          `json:"build_id"`
+	Platform string         `json:"platform"`
+	RemoteID string         `json:"remote_id"`
+	Error    string         `json:"error"`
+	Url      string         `json:"url"`
+	Warnings types.Warnings `json:"warnings"`
+}
+
+type Job struct {
+	ID        int64  `json:"id"`
+	BuildID   int64  `json:"build_id"`
+	Platform  string `json:"platform"`
+	Status    string `json:"status"`
+	Attempts  int64  `json:"attempts"`
+	NextRun   int64  `json:"next_run"`
+	LastError string `json:"last_error"`
+	CreatedAt int64  `json:"created_at"`
+	UpdatedAt int64  `json:"updated_at"`
+}
+
+type Release struct {
+	ID        int64  `json:"id"`
+	Project   string `json:"project"`
+	CreatedAt int64  `json:"created_at"`
 }
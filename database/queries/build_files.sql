@@ -0,0 +1,32 @@
+-- name: CreateBuildFile :exec
+INSERT INTO build_files (build_id, kind, filename, sha512, size)
+VALUES (?, ?, ?, ?, ?);
+
+-- name: ListBuildFiles :many
+SELECT build_files.build_id, build_files.kind, build_files.filename, build_files.sha512, build_files.size
+FROM build_files
+         INNER JOIN builds ON builds.id = build_files.build_id
+WHERE builds.project = ?
+ORDER BY build_files.build_id, build_files.kind;
+
+-- name: ListFilesForBuild :many
+SELECT build_id, kind, filename, sha512, size
+FROM build_files
+WHERE build_id = ?
+ORDER BY kind;
+
+-- name: ListAllBuildFiles :many
+SELECT build_id, kind, filename, sha512, size
+FROM build_files
+ORDER BY build_id, kind;
+
+-- name: DeleteBuildFile :exec
+DELETE
+FROM build_files
+WHERE build_id = ?
+  AND kind = ?;
+
+-- name: DeleteFilesForBuild :exec
+DELETE
+FROM build_files
+WHERE build_id = ?;
@@ -0,0 +1,25 @@
+-- name: SetBuildPlatform :exec
+INSERT INTO build_platforms (build_id, platform, remote_id, error, url, warnings)
+VALUES (?, ?, ?, ?, ?, ?)
+ON CONFLICT (build_id, platform) DO UPDATE SET remote_id = excluded.remote_id,
+                                               error     = excluded.error,
+                                               url       = excluded.url,
+                                               warnings  = excluded.warnings;
+
+-- name: ListBuildPlatforms :many
+SELECT build_platforms.build_id, build_platforms.platform, build_platforms.remote_id, build_platforms.error, build_platforms.url, build_platforms.warnings
+FROM build_platforms
+         INNER JOIN builds ON builds.id = build_platforms.build_id
+WHERE builds.project = ?
+ORDER BY build_platforms.build_id, build_platforms.platform;
+
+-- name: ListPlatformsForBuild :many
+SELECT build_id, platform, remote_id, error, url, warnings
+FROM build_platforms
+WHERE build_id = ?
+ORDER BY platform;
+
+-- name: DeletePlatformsForBuild :exec
+DELETE
+FROM build_platforms
+WHERE build_id = ?;
@@ -1,22 +1,47 @@
 -- name: CreateBuild :execlastid
-INSERT INTO builds (project, meta, filename, sha512, modrinth_id, curseforge_id)
-VALUES (?, ?, ?, ?, "", "");
-
--- name: UpdateModrinthFile :exec
-UPDATE builds
-SET modrinth_id = ?
-WHERE id = ?;
-
--- name: UpdateCurseforgeFile :exec
-UPDATE builds
-SET curseforge_id = ?
-WHERE id = ?;
+INSERT INTO builds (project, meta, filename, sha512, changelog, release_id, created_at, uploaded_by, git_commit, git_ref,
+                    ci_run_url, size)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
 
 -- name: ListBuilds :many
-SELECT meta, filename, sha512, modrinth_id, curseforge_id
+SELECT id, meta, filename, sha512, changelog, release_id, created_at, uploaded_by, git_commit, git_ref, ci_run_url, size
 FROM builds
 WHERE project = ?
 ORDER BY id;
 
+-- name: GetBuild :one
+SELECT id, project, meta, filename, sha512, changelog, release_id, created_at, uploaded_by, git_commit, git_ref, ci_run_url, size
+FROM builds
+WHERE project = ?
+  AND sha512 = ?;
+
+-- name: GetBuildByID :one
+SELECT id, project, meta, filename, sha512, changelog, release_id, created_at, uploaded_by, git_commit, git_ref, ci_run_url, size
+FROM builds
+WHERE id = ?;
+
 -- name: HashExists :one
 SELECT EXISTS(SELECT 1 FROM builds WHERE sha512 = ?);
+
+-- name: GetBuildByFileHash :one
+SELECT id, project, meta, filename, sha512, changelog, release_id, created_at, uploaded_by, git_commit, git_ref, ci_run_url, size
+FROM builds
+WHERE sha512 = ?1
+   OR id IN (SELECT build_id FROM build_files WHERE build_files.sha512 = ?1)
+ORDER BY id
+LIMIT 1;
+
+-- name: ListStoredBuilds :many
+SELECT id, project, sha512, size
+FROM builds
+ORDER BY id;
+
+-- name: DeleteBuild :exec
+DELETE
+FROM builds
+WHERE id = ?;
+
+-- name: SetBuildSize :exec
+UPDATE builds
+SET size = ?
+WHERE id = ?;
@@ -0,0 +1,52 @@
+-- name: CreateJob :execlastid
+INSERT INTO jobs (build_id, platform, status, attempts, next_run, last_error, created_at, updated_at)
+VALUES (?, ?, 'pending', 0, ?, '', ?, ?);
+
+-- name: ClaimJob :one
+-- Jobs of a build run one at a time so the build is not published twice
+UPDATE jobs
+SET status     = 'running',
+    updated_at = ?
+WHERE id = (SELECT id
+            FROM jobs
+            WHERE status = 'pending'
+              AND next_run <= ?
+              AND build_id NOT IN (SELECT build_id FROM jobs WHERE status = 'running')
+            ORDER BY next_run, id
+            LIMIT 1)
+RETURNING *;
+
+-- name: UpdateJob :exec
+UPDATE jobs
+SET status     = ?,
+    attempts   = ?,
+    next_run   = ?,
+    last_error = ?,
+    updated_at = ?
+WHERE id = ?;
+
+-- name: ResetRunningJobs :exec
+UPDATE jobs
+SET status = 'pending'
+WHERE status = 'running';
+
+-- name: GetJob :one
+SELECT jobs.id,
+       jobs.build_id,
+       jobs.platform,
+       jobs.status,
+       jobs.attempts,
+       jobs.next_run,
+       jobs.last_error,
+       jobs.created_at,
+       jobs.updated_at,
+       builds.project,
+       builds.sha512
+FROM jobs
+         INNER JOIN builds ON builds.id = jobs.build_id
+WHERE jobs.id = ?;
+
+-- name: DeleteJobsForBuild :exec
+DELETE
+FROM jobs
+WHERE build_id = ?;
@@ -0,0 +1,19 @@
+-- name: CreateRelease :execlastid
+INSERT INTO releases (project, created_at)
+VALUES (?, ?);
+
+-- name: GetRelease :one
+SELECT id, project, created_at
+FROM releases
+WHERE id = ?;
+
+-- name: ListReleaseBuilds :many
+SELECT id, project, meta, filename, sha512, changelog, release_id, created_at, uploaded_by, git_commit, git_ref, ci_run_url, size
+FROM builds
+WHERE release_id = ?
+ORDER BY id;
+
+-- name: DeleteEmptyReleases :exec
+DELETE
+FROM releases
+WHERE id NOT IN (SELECT release_id FROM builds);
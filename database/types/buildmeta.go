@@ -5,6 +5,7 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"fmt"
+	"time"
 )
 
 type BuildMeta struct {
@@ -13,6 +14,15 @@ type BuildMeta struct {
 	GameVersions   []string `json:"game_versions"`
 	Loaders        []string `json:"loaders"`
 	Environment    string   `json:"environment"`
+	Name           string   `json:"name,omitempty"`
+
+	// Featured, Status and ScheduledAt replace the project release defaults
+	Featured    *bool      `json:"featured,omitempty"`
+	Status      string     `json:"status,omitempty"`
+	ScheduledAt *time.Time `json:"scheduled_at,omitempty"`
+
+	// Overrides lists the upload fields which replaced the parsed metadata
+	Overrides []string `json:"overrides,omitempty"`
 }
 
 var _ driver.Valuer = &BuildMeta{}
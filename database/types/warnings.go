@@ -0,0 +1,31 @@
+package types
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+type Warnings []string
+
+var _ driver.Valuer = Warnings{}
+
+var _ sql.Scanner = &Warnings{}
+
+func (w Warnings) Value() (driver.Value, error) {
+	if w == nil {
+		w = Warnings{}
+	}
+	return json.Marshal(w)
+}
+
+func (w *Warnings) Scan(src any) error {
+	switch srcRaw := src.(type) {
+	case string:
+		return json.Unmarshal([]byte(srcRaw), w)
+	case []byte:
+		return json.Unmarshal(srcRaw, w)
+	}
+	return fmt.Errorf("invalid type")
+}
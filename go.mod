@@ -8,6 +8,7 @@ require (
 	github.com/deckarep/golang-set/v2 v2.8.0
 	github.com/golang-migrate/migrate/v4 v4.19.1
 	github.com/julienschmidt/httprouter v1.3.0
+	github.com/mattn/go-sqlite3 v1.14.32
 	github.com/mrmelon54/exit-reload v0.0.2
 	github.com/mrmelon54/rescheduler v0.0.3
 	github.com/stretchr/testify v1.11.1
@@ -17,6 +18,5 @@ require (
 
 require (
 	github.com/davecgh/go-spew v1.1.2-0.20180830191138-d8f796af33cc // indirect
-	github.com/mattn/go-sqlite3 v1.14.32 // indirect
 	github.com/pmezard/go-difflib v1.0.1-0.20181226105442-5d4384ee4fb2 // indirect
 )
@@ -0,0 +1,27 @@
+package mc_upload_api
+
+import (
+	"context"
+	"github.com/mrmelon54/mc-upload-api/database"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+// testDB returns an in-memory database which is separate for each test
+func testDB(t *testing.T) *database.Queries {
+	db, err := InitDB("file:" + t.Name() + "?mode=memory&cache=shared")
+	assert.NoError(t, err)
+	return db
+}
+
+func TestInitDB_UniqueBuildHash(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	build := database.CreateBuildParams{Project: "clock", Filename: "clock.jar", Sha512: strings.Repeat("ab", 64)}
+	_, err := db.CreateBuild(ctx, build)
+	assert.NoError(t, err)
+	_, err = db.CreateBuild(ctx, build)
+	assert.True(t, database.IsUniqueViolation(err))
+	assert.False(t, database.IsUniqueViolation(nil))
+}
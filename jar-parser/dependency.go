@@ -0,0 +1,60 @@
+package jar_parser
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"strings"
+)
+
+type DependencyType string
+
+const (
+	DependencyRequired     DependencyType = "required"
+	DependencyOptional     DependencyType = "optional"
+	DependencyIncompatible DependencyType = "incompatible"
+	DependencyEmbedded     DependencyType = "embedded"
+)
+
+type Dependency struct {
+	ModId string         `json:"mod_id"`
+	Type  DependencyType `json:"type"`
+}
+
+// platformModIds are provided by the loader or game and never published as dependencies
+var platformModIds = []string{"minecraft", "java", "fabricloader", "quilt_loader", "forge", "neoforge"}
+
+func (m *ModMetadata) addDependency(modId string, depType DependencyType) {
+	if slices.Contains(platformModIds, modId) {
+		return
+	}
+	for i := range m.Dependencies {
+		if m.Dependencies[i].ModId == modId {
+			m.Dependencies[i].Type = depType
+			return
+		}
+	}
+	m.Dependencies = append(m.Dependencies, Dependency{ModId: modId, Type: depType})
+}
+
+func (m *ModMetadata) addFabricDependencies(deps map[string]json.RawMessage, depType DependencyType) {
+	for _, modId := range slices.Sorted(maps.Keys(deps)) {
+		m.addDependency(modId, depType)
+	}
+}
+
+// forgeDependencyType reads the neoforge `type` field and falls back to the forge `mandatory` flag
+func forgeDependencyType(mandatory bool, t string) DependencyType {
+	switch strings.ToLower(t) {
+	case "required":
+		return DependencyRequired
+	case "optional":
+		return DependencyOptional
+	case "incompatible", "discouraged":
+		return DependencyIncompatible
+	}
+	if mandatory {
+		return DependencyRequired
+	}
+	return DependencyOptional
+}
@@ -1,5 +1,7 @@
 package jar_parser
 
+import "encoding/json"
+
 type FabricJson struct {
 	SchemaVersion int      `json:"schemaVersion"`
 	Id            string   `json:"id"`
@@ -19,11 +21,17 @@ type FabricJson struct {
 		Main    []string `json:"main"`
 		Modmenu []string `json:"modmenu"`
 	} `json:"entrypoints"`
-	Mixins  []string `json:"mixins"`
-	Depends struct {
-		Fabric       *FabricVersionRange `json:"fabric"`
-		Minecraft    *FabricVersionRange `json:"minecraft"`
-		Architectury *FabricVersionRange `json:"architectury"`
-	} `json:"depends"`
+	Mixins []string `json:"mixins"`
+
+	// Depends, Recommends, Suggests and Breaks map mod IDs to version predicates,
+	// only the minecraft predicate is parsed as other dependencies are published
+	// by their ID
+	Depends    map[string]json.RawMessage `json:"depends"`
+	Recommends map[string]json.RawMessage `json:"recommends"`
+	Suggests   map[string]json.RawMessage `json:"suggests"`
+	Breaks     map[string]json.RawMessage `json:"breaks"`
+	Jars       []struct {
+		File string `json:"file"`
+	} `json:"jars"`
 	AccessWidener string `json:"accessWidener"`
 }
@@ -16,6 +16,7 @@ type ForgeToml struct {
 	Dependencies map[string][]struct {
 		ModID        string `toml:"modId"`
 		Mandatory    bool   `toml:"mandatory"`
+		Type         string `toml:"type"`
 		VersionRange string `toml:"versionRange"`
 		Ordering     string `toml:"ordering"`
 		Side         string `toml:"side"`
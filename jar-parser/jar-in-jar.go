@@ -0,0 +1,110 @@
+package jar_parser
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/BurntSushi/toml"
+	"io"
+	"io/fs"
+)
+
+// MaxNestedJarSize limits the uncompressed size of a nested jar, they are read
+// into memory to find the mod ID
+const MaxNestedJarSize = 32 << 20 // 32 MiB
+
+var errNestedJarTooBig = errors.New("nested jar too big")
+
+type JarJarMetadata struct {
+	Jars []struct {
+		Identifier struct {
+			Group    string `json:"group"`
+			Artifact string `json:"artifact"`
+		} `json:"identifier"`
+		Path string `json:"path"`
+	} `json:"jars"`
+}
+
+// readJarJarMetadata lists the nested jar paths used by forge/neoforge jar-in-jar
+func readJarJarMetadata(zr *zip.Reader) ([]string, error) {
+	openMetadata, err := zr.Open("META-INF/jarjar/metadata.json")
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer openMetadata.Close()
+
+	var jarJar JarJarMetadata
+	if err := json.NewDecoder(openMetadata).Decode(&jarJar); err != nil {
+		return nil, err
+	}
+	a := make([]string, len(jarJar.Jars))
+	for i := range jarJar.Jars {
+		a[i] = jarJar.Jars[i].Path
+	}
+	return a, nil
+}
+
+// nestedJarModId finds the mod ID of a jar embedded at the given path, an empty
+// string is returned if the nested jar is a plain library without mod metadata
+func nestedJarModId(zr *zip.Reader, name string) (string, error) {
+	openJar, err := zr.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer openJar.Close()
+	info, err := openJar.Stat()
+	if err != nil {
+		return "", err
+	}
+	if info.Size() > MaxNestedJarSize {
+		return "", errNestedJarTooBig
+	}
+	jarBytes, err := io.ReadAll(io.LimitReader(openJar, MaxNestedJarSize+1))
+	if err != nil {
+		return "", err
+	}
+	if len(jarBytes) > MaxNestedJarSize {
+		return "", errNestedJarTooBig
+	}
+	nested, err := zip.NewReader(bytes.NewReader(jarBytes), int64(len(jarBytes)))
+	if err != nil {
+		return "", err
+	}
+
+	if openFabricModJson, err := nested.Open("fabric.mod.json"); err == nil {
+		defer openFabricModJson.Close()
+		var fabricJson struct {
+			Id string `json:"id"`
+		}
+		if err := json.NewDecoder(openFabricModJson).Decode(&fabricJson); err != nil {
+			return "", err
+		}
+		return fabricJson.Id, nil
+	}
+	if openQuiltModJson, err := nested.Open("quilt.mod.json"); err == nil {
+		defer openQuiltModJson.Close()
+		var quiltJson struct {
+			QuiltLoader struct {
+				Id string `json:"id"`
+			} `json:"quilt_loader"`
+		}
+		if err := json.NewDecoder(openQuiltModJson).Decode(&quiltJson); err != nil {
+			return "", err
+		}
+		return quiltJson.QuiltLoader.Id, nil
+	}
+	if openModsToml, err := nested.Open("META-INF/mods.toml"); err == nil {
+		defer openModsToml.Close()
+		var forgeToml ForgeToml
+		if _, err := toml.NewDecoder(openModsToml).Decode(&forgeToml); err != nil {
+			return "", err
+		}
+		if len(forgeToml.Mods) > 0 {
+			return forgeToml.Mods[0].ModID, nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,72 @@
+package jar_parser
+
+import (
+	"archive/zip"
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func buildTestZip(t *testing.T, files map[string][]byte) []byte {
+	buf := new(bytes.Buffer)
+	zw := zip.NewWriter(buf)
+	for k, v := range files {
+		w, err := zw.Create(k)
+		assert.NoError(t, err)
+		_, err = w.Write(v)
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func TestNestedJarModId(t *testing.T) {
+	outer := buildTestZip(t, map[string][]byte{
+		"META-INF/jarjar/metadata.json": []byte(`{"jars":[{"identifier":{"group":"me.shedaniel.cloth","artifact":"cloth-config-forge"},"path":"META-INF/jarjar/cloth-config.jar"},{"identifier":{"group":"com.google","artifact":"gson"},"path":"META-INF/jarjar/gson.jar"}]}`),
+		"META-INF/jarjar/cloth-config.jar": buildTestZip(t, map[string][]byte{
+			"META-INF/mods.toml": []byte("[[mods]]\nmodId = \"cloth_config\"\nversion = \"11.1.118\"\n"),
+		}),
+		"META-INF/jarjar/gson.jar": buildTestZip(t, map[string][]byte{
+			"com/google/gson/Gson.class": {0xca, 0xfe},
+		}),
+		"META-INF/jars/fabric-api-base.jar": buildTestZip(t, map[string][]byte{
+			"fabric.mod.json": []byte(`{"id":"fabric-api-base"}`),
+		}),
+	})
+	zr, err := zip.NewReader(bytes.NewReader(outer), int64(len(outer)))
+	assert.NoError(t, err)
+
+	paths, err := readJarJarMetadata(zr)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"META-INF/jarjar/cloth-config.jar", "META-INF/jarjar/gson.jar"}, paths)
+
+	modId, err := nestedJarModId(zr, "META-INF/jarjar/cloth-config.jar")
+	assert.NoError(t, err)
+	assert.Equal(t, "cloth_config", modId)
+
+	modId, err = nestedJarModId(zr, "META-INF/jarjar/gson.jar")
+	assert.NoError(t, err)
+	assert.Equal(t, "", modId)
+
+	modId, err = nestedJarModId(zr, "META-INF/jars/fabric-api-base.jar")
+	assert.NoError(t, err)
+	assert.Equal(t, "fabric-api-base", modId)
+
+	_, err = nestedJarModId(zr, "META-INF/jars/missing.jar")
+	assert.Error(t, err)
+}
+
+func TestNestedJarModId_TooBig(t *testing.T) {
+	buf := new(bytes.Buffer)
+	zw := zip.NewWriter(buf)
+	w, err := zw.Create("META-INF/jars/big.jar")
+	assert.NoError(t, err)
+	_, err = w.Write(make([]byte, MaxNestedJarSize+1))
+	assert.NoError(t, err)
+	assert.NoError(t, zw.Close())
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoError(t, err)
+
+	_, err = nestedJarModId(zr, "META-INF/jars/big.jar")
+	assert.ErrorIs(t, err, errNestedJarTooBig)
+}
@@ -7,16 +7,22 @@ import (
 	"github.com/BurntSushi/toml"
 	"github.com/Masterminds/semver/v3"
 	"github.com/wreulicke/classfile-parser"
+	"gopkg.in/yaml.v3"
 	"io"
+	"maps"
+	"slices"
 	"strings"
 )
 
 type ModMetadata struct {
+	// Name is the display name of the mod
+	Name           string
 	VersionNumber  string
 	ReleaseChannel string
 	GameVersions   []*semver.Constraints
 	Loaders        []string
 	Environment    string
+	Dependencies   []Dependency
 }
 
 func JarParser(r io.ReaderAt, size int64) (ModMetadata, error) {
@@ -25,9 +31,8 @@ func JarParser(r io.ReaderAt, size int64) (ModMetadata, error) {
 		return ModMetadata{}, err
 	}
 
-	meta := ModMetadata{
-		ReleaseChannel: "release",
-	}
+	var meta ModMetadata
+	var nestedJars []string
 
 	// try loading fabric
 	openFabricModJson, err := zr.Open("fabric.mod.json")
@@ -37,6 +42,9 @@ func JarParser(r io.ReaderAt, size int64) (ModMetadata, error) {
 			return ModMetadata{}, fmt.Errorf("failed to decode fabric.mod.json: %w", err)
 		}
 		_ = openFabricModJson.Close()
+		for _, j := range fabricJson.Jars {
+			nestedJars = append(nestedJars, j.File)
+		}
 		for _, entrypoint := range fabricJson.Entrypoints.Main {
 			entrypoint = strings.ReplaceAll(entrypoint, ".", "/")
 			class, err := loadClass(zr, entrypoint)
@@ -45,9 +53,20 @@ func JarParser(r io.ReaderAt, size int64) (ModMetadata, error) {
 			}
 			if implementsInterface(class, "net/fabricmc/api/ModInitializer") {
 				meta.VersionNumber = fabricJson.Version
+				meta.Name = fabricJson.Name
 				meta.Loaders = append(meta.Loaders, "fabric")
 				meta.Environment = fabricJson.Environment
-				meta.GameVersions = append(meta.GameVersions, fabricJson.Depends.Minecraft.C)
+				if b, ok := fabricJson.Depends["minecraft"]; ok {
+					var mc FabricVersionRange
+					if err := json.Unmarshal(b, &mc); err != nil {
+						return ModMetadata{}, fmt.Errorf("failed to parse minecraft version range: %w", err)
+					}
+					meta.GameVersions = append(meta.GameVersions, mc.C)
+				}
+				meta.addFabricDependencies(fabricJson.Depends, DependencyRequired)
+				meta.addFabricDependencies(fabricJson.Recommends, DependencyOptional)
+				meta.addFabricDependencies(fabricJson.Suggests, DependencyOptional)
+				meta.addFabricDependencies(fabricJson.Breaks, DependencyIncompatible)
 			}
 		}
 	}
@@ -60,6 +79,7 @@ func JarParser(r io.ReaderAt, size int64) (ModMetadata, error) {
 			return ModMetadata{}, err
 		}
 		_ = openQuiltModJson.Close()
+		nestedJars = append(nestedJars, quiltJson.QuiltLoader.Jars...)
 		for _, entrypoint := range quiltJson.QuiltLoader.Entrypoints.Init {
 			entrypoint = strings.ReplaceAll(entrypoint, ".", "/")
 			class, err := loadClass(zr, entrypoint)
@@ -68,12 +88,18 @@ func JarParser(r io.ReaderAt, size int64) (ModMetadata, error) {
 			}
 			if implementsInterface(class, "org/quiltmc/qsl/base/api/entrypoint/ModInitializer") {
 				meta.VersionNumber = quiltJson.QuiltLoader.Version
+				meta.Name = quiltJson.QuiltLoader.Metadata.Name
 				meta.Loaders = append(meta.Loaders, "quilt")
 				meta.Environment = quiltJson.Minecraft.Environment
 				for _, j := range quiltJson.QuiltLoader.Depends {
 					if j.Id == "minecraft" {
 						meta.GameVersions = append(meta.GameVersions, j.Version.C)
 					}
+					if j.Optional {
+						meta.addDependency(j.Id, DependencyOptional)
+					} else {
+						meta.addDependency(j.Id, DependencyRequired)
+					}
 				}
 			}
 		}
@@ -88,6 +114,12 @@ func JarParser(r io.ReaderAt, size int64) (ModMetadata, error) {
 		}
 		_ = openModsToml.Close()
 
+		jarJarPaths, err := readJarJarMetadata(zr)
+		if err != nil {
+			return ModMetadata{}, err
+		}
+		nestedJars = append(nestedJars, jarJarPaths...)
+
 		var entrypointLoader string
 	entrypointFinder:
 		for _, i := range zr.File {
@@ -120,6 +152,7 @@ func JarParser(r io.ReaderAt, size int64) (ModMetadata, error) {
 
 		modId := forgeToml.Mods[0].ModID
 		meta.VersionNumber = forgeToml.Mods[0].Version
+		meta.Name = forgeToml.Mods[0].DisplayName
 		meta.Loaders = append(meta.Loaders, entrypointLoader)
 		for k, v := range forgeToml.Dependencies {
 			if k == modId {
@@ -131,10 +164,104 @@ func JarParser(r io.ReaderAt, size int64) (ModMetadata, error) {
 						}
 						meta.GameVersions = append(meta.GameVersions, versionRange)
 					}
+					meta.addDependency(j.ModID, forgeDependencyType(j.Mandatory, j.Type))
 				}
 			}
 		}
 	}
+
+	// try loading paper/bukkit plugin
+	for _, pluginYmlName := range []string{"paper-plugin.yml", "plugin.yml"} {
+		openPluginYml, err := zr.Open(pluginYmlName)
+		if err != nil {
+			continue
+		}
+		var pluginYml PluginYml
+		if err := yaml.NewDecoder(openPluginYml).Decode(&pluginYml); err != nil {
+			return ModMetadata{}, fmt.Errorf("failed to decode %s: %w", pluginYmlName, err)
+		}
+		_ = openPluginYml.Close()
+
+		meta.VersionNumber = pluginYml.Version
+		meta.Name = pluginYml.Name
+		meta.Loaders = append(meta.Loaders, "paper")
+		meta.Environment = "server"
+		if pluginYml.ApiVersion != "" {
+			// api-version is the lowest supported game version
+			apiVersion, err := semver.NewConstraint(">=" + pluginYml.ApiVersion)
+			if err != nil {
+				return ModMetadata{}, fmt.Errorf("failed to parse api-version '%s': %w", pluginYml.ApiVersion, err)
+			}
+			meta.GameVersions = append(meta.GameVersions, apiVersion)
+		}
+		for _, j := range pluginYml.Depend {
+			meta.addDependency(j, DependencyRequired)
+		}
+		for _, j := range pluginYml.SoftDepend {
+			meta.addDependency(j, DependencyOptional)
+		}
+		for _, j := range slices.Sorted(maps.Keys(pluginYml.Dependencies.Server)) {
+			if required := pluginYml.Dependencies.Server[j].Required; required == nil || *required {
+				meta.addDependency(j, DependencyRequired)
+			} else {
+				meta.addDependency(j, DependencyOptional)
+			}
+		}
+		break
+	}
+
+	// try loading velocity plugin
+	openVelocityJson, err := zr.Open("velocity-plugin.json")
+	if err == nil {
+		var velocityJson VelocityJson
+		if err := json.NewDecoder(openVelocityJson).Decode(&velocityJson); err != nil {
+			return ModMetadata{}, fmt.Errorf("failed to decode velocity-plugin.json: %w", err)
+		}
+		_ = openVelocityJson.Close()
+		meta.VersionNumber = velocityJson.Version
+		meta.Name = velocityJson.Name
+		meta.Loaders = append(meta.Loaders, "velocity")
+		meta.Environment = "server"
+		for _, j := range velocityJson.Dependencies {
+			if j.Optional {
+				meta.addDependency(j.Id, DependencyOptional)
+			} else {
+				meta.addDependency(j.Id, DependencyRequired)
+			}
+		}
+	}
+
+	// try loading waterfall/bungeecord plugin
+	openBungeeYml, err := zr.Open("bungee.yml")
+	if err == nil {
+		var bungeeYml BungeeYml
+		if err := yaml.NewDecoder(openBungeeYml).Decode(&bungeeYml); err != nil {
+			return ModMetadata{}, fmt.Errorf("failed to decode bungee.yml: %w", err)
+		}
+		_ = openBungeeYml.Close()
+		meta.VersionNumber = bungeeYml.Version
+		meta.Name = bungeeYml.Name
+		meta.Loaders = append(meta.Loaders, "waterfall")
+		meta.Environment = "server"
+		for _, j := range bungeeYml.Depends {
+			meta.addDependency(j, DependencyRequired)
+		}
+		for _, j := range bungeeYml.SoftDepends {
+			meta.addDependency(j, DependencyOptional)
+		}
+	}
+
+	for _, i := range nestedJars {
+		modId, err := nestedJarModId(zr, i)
+		if err != nil {
+			return ModMetadata{}, fmt.Errorf("failed to read nested jar '%s': %w", i, err)
+		}
+		if modId != "" {
+			meta.addDependency(modId, DependencyEmbedded)
+		}
+	}
+
+	meta.ReleaseChannel = ReleaseChannelFromVersion(meta.VersionNumber)
 	return meta, nil
 }
 
@@ -1,9 +1,12 @@
 package jar_parser
 
 import (
+	"archive/zip"
 	"bytes"
 	"embed"
+	"encoding/json"
 	"github.com/stretchr/testify/assert"
+	"io"
 	"testing"
 )
 
@@ -23,3 +26,100 @@ func TestJarParser(t *testing.T) {
 		})
 	}
 }
+
+var platformDependencies = map[string][]Dependency{
+	"fabric": {
+		{ModId: "architectury", Type: DependencyRequired},
+		{ModId: "cloth-config", Type: DependencyRequired},
+		{ModId: "fabric", Type: DependencyRequired},
+	},
+	"forge": {
+		{ModId: "architectury", Type: DependencyRequired},
+		{ModId: "cloth_config", Type: DependencyRequired},
+	},
+	"neoforge": {
+		{ModId: "architectury", Type: DependencyRequired},
+		{ModId: "cloth_config", Type: DependencyRequired},
+	},
+	"quilt": {
+		{ModId: "quilt_base", Type: DependencyRequired},
+		{ModId: "architectury", Type: DependencyRequired},
+		{ModId: "cloth-config", Type: DependencyRequired},
+	},
+}
+
+func TestJarParser_Dependencies(t *testing.T) {
+	for _, i := range platforms {
+		testJarBytes, err := testJars.ReadFile("test-" + i + ".jar")
+		assert.NoError(t, err)
+		t.Run(i, func(t *testing.T) {
+			metadata, err := JarParser(bytes.NewReader(testJarBytes), int64(len(testJarBytes)))
+			assert.NoError(t, err)
+			assert.Equal(t, platformDependencies[i], metadata.Dependencies)
+		})
+	}
+}
+
+func TestJarParser_FabricPredicates(t *testing.T) {
+	testJarBytes, err := testJars.ReadFile("test-fabric.jar")
+	assert.NoError(t, err)
+	zr, err := zip.NewReader(bytes.NewReader(testJarBytes), int64(len(testJarBytes)))
+	assert.NoError(t, err)
+
+	// copy the jar with dependency predicates the semver library cannot parse
+	buf := new(bytes.Buffer)
+	zw := zip.NewWriter(buf)
+	for _, f := range zr.File {
+		r, err := f.Open()
+		assert.NoError(t, err)
+		w, err := zw.Create(f.Name)
+		assert.NoError(t, err)
+		if f.Name == "fabric.mod.json" {
+			var fabricJson map[string]any
+			assert.NoError(t, json.NewDecoder(r).Decode(&fabricJson))
+			fabricJson["depends"].(map[string]any)["fabricloader"] = ">=0.15.0-"
+			fabricJson["recommends"] = map[string]any{"modmenu": []string{"~9.0.0-pre.1", ">=9.2.0-beta.2 <10-"}}
+			assert.NoError(t, json.NewEncoder(w).Encode(fabricJson))
+		} else {
+			_, err = io.Copy(w, r)
+			assert.NoError(t, err)
+		}
+		assert.NoError(t, r.Close())
+	}
+	assert.NoError(t, zw.Close())
+
+	metadata, err := JarParser(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoError(t, err)
+	assert.Len(t, metadata.GameVersions, 1)
+	assert.Equal(t, append(platformDependencies["fabric"], Dependency{ModId: "modmenu", Type: DependencyOptional}), metadata.Dependencies)
+}
+
+func TestJarParser_Plugins(t *testing.T) {
+	t.Run("paper", func(t *testing.T) {
+		jar := buildTestZip(t, map[string][]byte{
+			"plugin.yml": []byte("name: TestPlugin\nversion: 1.2.0\nmain: com.example.TestPlugin\napi-version: 1.20\ndepend: [Vault]\nsoftdepend: [PlaceholderAPI]\n"),
+		})
+		metadata, err := JarParser(bytes.NewReader(jar), int64(len(jar)))
+		assert.NoError(t, err)
+		assert.Equal(t, "TestPlugin", metadata.Name)
+		assert.Equal(t, "1.2.0", metadata.VersionNumber)
+		assert.Equal(t, []string{"paper"}, metadata.Loaders)
+		assert.Equal(t, "server", metadata.Environment)
+		assert.Len(t, metadata.GameVersions, 1)
+		assert.Equal(t, ">=1.20", metadata.GameVersions[0].String())
+		assert.Equal(t, []Dependency{
+			{ModId: "Vault", Type: DependencyRequired},
+			{ModId: "PlaceholderAPI", Type: DependencyOptional},
+		}, metadata.Dependencies)
+	})
+	t.Run("velocity", func(t *testing.T) {
+		jar := buildTestZip(t, map[string][]byte{
+			"velocity-plugin.json": []byte(`{"id":"testplugin","version":"1.2.0","main":"com.example.TestPlugin","dependencies":[{"id":"luckperms","optional":true}]}`),
+		})
+		metadata, err := JarParser(bytes.NewReader(jar), int64(len(jar)))
+		assert.NoError(t, err)
+		assert.Equal(t, "1.2.0", metadata.VersionNumber)
+		assert.Equal(t, []string{"velocity"}, metadata.Loaders)
+		assert.Equal(t, []Dependency{{ModId: "luckperms", Type: DependencyOptional}}, metadata.Dependencies)
+	})
+}
@@ -0,0 +1,24 @@
+package jar_parser
+
+// PluginYml covers both the bukkit plugin.yml and paper-plugin.yml formats
+type PluginYml struct {
+	Name         string   `yaml:"name"`
+	Version      string   `yaml:"version"`
+	Main         string   `yaml:"main"`
+	ApiVersion   string   `yaml:"api-version"`
+	Depend       []string `yaml:"depend"`
+	SoftDepend   []string `yaml:"softdepend"`
+	Dependencies struct {
+		Server map[string]struct {
+			Required *bool `yaml:"required"`
+		} `yaml:"server"`
+	} `yaml:"dependencies"`
+}
+
+type BungeeYml struct {
+	Name        string   `yaml:"name"`
+	Version     string   `yaml:"version"`
+	Main        string   `yaml:"main"`
+	Depends     []string `yaml:"depends"`
+	SoftDepends []string `yaml:"softDepends"`
+}
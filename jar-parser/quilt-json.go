@@ -25,9 +25,11 @@ type QuiltJson struct {
 			Modmenu []string `json:"modmenu"`
 		} `json:"entrypoints"`
 		Depends []struct {
-			Id      string              `json:"id"`
-			Version *FabricVersionRange `json:"version"`
+			Id       string              `json:"id"`
+			Version  *FabricVersionRange `json:"version"`
+			Optional bool                `json:"optional"`
 		} `json:"depends"`
+		Jars []string `json:"jars"`
 	} `json:"quilt_loader"`
 	Minecraft struct {
 		Environment string `json:"environment"`
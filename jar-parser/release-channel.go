@@ -0,0 +1,33 @@
+package jar_parser
+
+import (
+	"github.com/Masterminds/semver/v3"
+	"strings"
+)
+
+// prereleaseChannels maps semver prerelease identifiers to release channels, the
+// platforms have no separate channel for release candidates
+var prereleaseChannels = map[string]string{
+	"alpha": "alpha",
+	"beta":  "beta",
+	"rc":    "beta",
+	"pre":   "beta",
+}
+
+// ReleaseChannelFromVersion finds the release channel from the prerelease part of
+// a semver version, versions without a known prerelease identifier are releases
+func ReleaseChannelFromVersion(version string) string {
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return "release"
+	}
+	identifiers := strings.FieldsFunc(strings.ToLower(v.Prerelease()), func(r rune) bool {
+		return r == '.' || r == '-'
+	})
+	for _, i := range identifiers {
+		if channel, ok := prereleaseChannels[strings.TrimRight(i, "0123456789")]; ok {
+			return channel
+		}
+	}
+	return "release"
+}
@@ -0,0 +1,23 @@
+package jar_parser
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestReleaseChannelFromVersion(t *testing.T) {
+	for version, channel := range map[string]string{
+		"1.4.0":            "release",
+		"1.4.0-beta.2":     "beta",
+		"2.0.0-alpha":      "alpha",
+		"2.0.0-ALPHA1":     "alpha",
+		"2.0.0-rc.1":       "beta",
+		"2.0.0-pre3":       "beta",
+		"2.0.0-mc1.20.4":   "release",
+		"2.0.0-beta+1.20":  "beta",
+		"2.0.0-mc1.20-rc1": "beta",
+		"not a version":    "release",
+	} {
+		assert.Equal(t, channel, ReleaseChannelFromVersion(version), version)
+	}
+}
@@ -0,0 +1,12 @@
+package jar_parser
+
+type VelocityJson struct {
+	Id           string `json:"id"`
+	Name         string `json:"name"`
+	Version      string `json:"version"`
+	Main         string `json:"main"`
+	Dependencies []struct {
+		Id       string `json:"id"`
+		Optional bool   `json:"optional"`
+	} `json:"dependencies"`
+}
@@ -1,24 +1,260 @@
 package mc_upload_api
 
+import (
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	jarparser "github.com/mrmelon54/mc-upload-api/jar-parser"
+	"github.com/mrmelon54/mc-upload-api/uploader"
+	"gopkg.in/yaml.v3"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+)
+
 type ProjectsConfig map[string]Project
 
 type Project struct {
 	ProjectDetails `yaml:",inline"`
 	Token          string `yaml:"token"`
+
+	// Uploader names the holder of the token, it is public as the uploaded_by
+	// field of builds
+	Uploader string `yaml:"uploader"`
+
+	// MaxFilesize limits the size of uploaded jars in bytes
+	MaxFilesize int64 `yaml:"maxFilesize"`
+
+	// ReleaseChannels are checked in order before the channel is inferred from
+	// the version number
+	ReleaseChannels []ReleaseChannelRule `yaml:"releaseChannels"`
+
+	// VersionName is a text/template executed with VersionNameData
+	VersionName Template `yaml:"version_name"`
+}
+
+func (p *Project) UnmarshalYAML(value *yaml.Node) error {
+	if err := rejectLegacyKeys(value, "modrinth", "curseforge", "hangar"); err != nil {
+		return err
+	}
+	type rawProject Project
+	raw := rawProject(*p)
+	if err := value.Decode(&raw); err != nil {
+		return err
+	}
+	// the github field is the default repository of the github platform
+	if raw.Github != "" && raw.Platforms["github"].Id == "" {
+		if raw.Platforms == nil {
+			raw.Platforms = make(map[string]ProjectPlatform)
+		}
+		platform := raw.Platforms["github"]
+		platform.Id = raw.Github
+		raw.Platforms["github"] = platform
+	}
+	*p = Project(raw)
+	return nil
+}
+
+// VersionNameData is available to the version_name template
+type VersionNameData struct {
+	Name         string
+	Version      string
+	Channel      string
+	Loaders      []string
+	GameVersions []string
+
+	// GameVersionRange is the lowest and highest game version, like "1.20.1-1.20.4"
+	GameVersionRange string
+
+	Filename string
+}
+
+func NewVersionNameData(meta jarparser.ModMetadata, gameVersions []string, filename string) VersionNameData {
+	return VersionNameData{
+		Name:             meta.Name,
+		Version:          meta.VersionNumber,
+		Channel:          meta.ReleaseChannel,
+		Loaders:          meta.Loaders,
+		GameVersions:     gameVersions,
+		GameVersionRange: gameVersionRange(gameVersions),
+		Filename:         filename,
+	}
+}
+
+func gameVersionRange(gameVersions []string) string {
+	var low, high *semver.Version
+	for _, i := range gameVersions {
+		v, err := semver.NewVersion(i)
+		if err != nil {
+			continue
+		}
+		if low == nil || v.LessThan(low) {
+			low = v
+		}
+		if high == nil || v.GreaterThan(high) {
+			high = v
+		}
+	}
+	switch {
+	case low == nil:
+		return ""
+	case low.Equal(high):
+		return low.Original()
+	}
+	return low.Original() + "-" + high.Original()
+}
+
+// RenderVersionName executes the version_name template, the name is empty if the
+// project has no template
+func (p Project) RenderVersionName(data VersionNameData) (string, error) {
+	if p.VersionName.Template == nil {
+		return "", nil
+	}
+	var sb strings.Builder
+	if err := p.VersionName.Execute(&sb, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(sb.String()), nil
+}
+
+// ReleaseChannel returns the channel of the first rule matching the version number
+func (p Project) ReleaseChannel(versionNumber string) (string, bool) {
+	for _, i := range p.ReleaseChannels {
+		if i.Match.MatchString(versionNumber) {
+			return i.Channel, true
+		}
+	}
+	return "", false
+}
+
+type ReleaseChannelRule struct {
+	Match   Regexp `yaml:"match"`
+	Channel string `yaml:"channel"`
+}
+
+func (r *ReleaseChannelRule) UnmarshalYAML(value *yaml.Node) error {
+	type rawRule ReleaseChannelRule
+	var raw rawRule
+	if err := value.Decode(&raw); err != nil {
+		return err
+	}
+	if raw.Match.Regexp == nil {
+		return fmt.Errorf("release channel rule on line %d is missing a match pattern", value.Line)
+	}
+	switch raw.Channel {
+	case "release", "beta", "alpha":
+	default:
+		return fmt.Errorf("invalid release channel on line %d: %s", value.Line, raw.Channel)
+	}
+	*r = ReleaseChannelRule(raw)
+	return nil
+}
+
+var templateFuncs = template.FuncMap{
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"title": func(s string) string {
+		if s == "" {
+			return s
+		}
+		return strings.ToUpper(s[:1]) + s[1:]
+	},
+}
+
+// Template is parsed when the config is loaded
+type Template struct {
+	*template.Template
+}
+
+func (t *Template) UnmarshalYAML(value *yaml.Node) error {
+	var s string
+	if err := value.Decode(&s); err != nil {
+		return err
+	}
+	tmpl, err := template.New("version_name").Funcs(templateFuncs).Parse(s)
+	if err != nil {
+		return fmt.Errorf("invalid template on line %d: %w", value.Line, err)
+	}
+	t.Template = tmpl
+	return nil
+}
+
+// Regexp is compiled when the config is loaded
+type Regexp struct {
+	*regexp.Regexp
+}
+
+func (r *Regexp) UnmarshalYAML(value *yaml.Node) error {
+	var s string
+	if err := value.Decode(&s); err != nil {
+		return err
+	}
+	re, err := regexp.Compile(s)
+	if err != nil {
+		return fmt.Errorf("invalid pattern on line %d: %w", value.Line, err)
+	}
+	r.Regexp = re
+	return nil
 }
 
 type ProjectDetails struct {
-	Name       string          `yaml:"name" json:"name"`
-	Modrinth   ProjectPlatform `yaml:"modrinth" json:"modrinth"`
-	Curseforge ProjectPlatform `yaml:"curseforge" json:"curseforge"`
-	Github     string          `yaml:"github" json:"github"`
+	Name string `yaml:"name" json:"name"`
+
+	// Github is the repository of the github platform when it has no id
+	Github string `yaml:"github" json:"github"`
+
+	// Homepage is linked from the Forge update feed
+	Homepage string `yaml:"homepage" json:"homepage,omitempty"`
+
+	// Platforms are keyed by the platform name from the config
+	Platforms map[string]ProjectPlatform `yaml:"platforms" json:"platforms"`
+}
+
+// EnabledPlatforms returns the sorted names of the enabled platforms
+func (p ProjectDetails) EnabledPlatforms() []string {
+	a := make([]string, 0, len(p.Platforms))
+	for k, v := range p.Platforms {
+		if v.Enabled() {
+			a = append(a, k)
+		}
+	}
+	slices.Sort(a)
+	return a
 }
 
 type ProjectPlatform struct {
 	Url string `yaml:"url" json:"url"`
 	Id  string `yaml:"id" json:"id"`
+
+	// Dependencies maps mod IDs to the project ID, slug or name on this platform
+	Dependencies map[string]string `yaml:"dependencies" json:"dependencies,omitempty"`
+
+	// Channels maps release channels to named channels, used by Hangar
+	Channels map[string]string `yaml:"channels" json:"channels,omitempty"`
+
+	// PlatformVersions lists supported proxy versions, used by Hangar for VELOCITY and WATERFALL
+	PlatformVersions map[string][]string `yaml:"platformVersions" json:"platformVersions,omitempty"`
+
+	// Featured, Status and ScheduleDelay are the release defaults, used by Modrinth
+	Featured      bool          `yaml:"featured" json:"featured,omitempty"`
+	Status        string        `yaml:"status" json:"status,omitempty"`
+	ScheduleDelay time.Duration `yaml:"scheduleDelay" json:"scheduleDelay,omitempty"`
 }
 
 func (p ProjectPlatform) Enabled() bool {
 	return p.Id != ""
 }
+
+func (p ProjectPlatform) Target() uploader.Project {
+	return uploader.Project{
+		Id:               p.Id,
+		Url:              p.Url,
+		Dependencies:     p.Dependencies,
+		Channels:         p.Channels,
+		PlatformVersions: p.PlatformVersions,
+		Featured:         p.Featured,
+		Status:           p.Status,
+		ScheduleDelay:    p.ScheduleDelay,
+	}
+}
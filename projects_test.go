@@ -0,0 +1,57 @@
+package mc_upload_api
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+	"testing"
+)
+
+func TestProject_Github(t *testing.T) {
+	var projects ProjectsConfig
+	assert.NoError(t, yaml.Unmarshal([]byte(`
+clock:
+  github: mrmelon54/clock
+both:
+  github: mrmelon54/both
+  platforms:
+    github:
+      id: mrmelon54/other
+`), &projects))
+	assert.Equal(t, "mrmelon54/clock", projects["clock"].Platforms["github"].Id)
+	assert.Equal(t, []string{"github"}, projects["clock"].EnabledPlatforms())
+	assert.Equal(t, "mrmelon54/other", projects["both"].Platforms["github"].Id)
+}
+
+func TestProject_UnmarshalYAML_Merge(t *testing.T) {
+	project := Project{Token: "abcd"}
+	assert.NoError(t, yaml.Unmarshal([]byte("name: Clock\n"), &project))
+	assert.Equal(t, "abcd", project.Token)
+	assert.Equal(t, "Clock", project.Name)
+}
+
+func TestProject_RenderVersionName(t *testing.T) {
+	var project Project
+	assert.NoError(t, yaml.Unmarshal([]byte(`version_name: "{{ .Name }} {{ .Version }} ({{ join .Loaders \", \" | title }} {{ .GameVersionRange }}) {{ upper .Channel }}"`), &project))
+	name, err := project.RenderVersionName(VersionNameData{
+		Name:             "Clock",
+		Version:          "1.2.3",
+		Channel:          "beta",
+		Loaders:          []string{"fabric", "quilt"},
+		GameVersionRange: "1.20.1-1.20.4",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "Clock 1.2.3 (Fabric, quilt 1.20.1-1.20.4) BETA", name)
+
+	name, err = Project{}.RenderVersionName(VersionNameData{Name: "Clock"})
+	assert.NoError(t, err)
+	assert.Equal(t, "", name)
+
+	assert.ErrorContains(t, yaml.Unmarshal([]byte(`version_name: "{{ .Name"`), &project), "invalid template on line 1")
+}
+
+func TestGameVersionRange(t *testing.T) {
+	assert.Equal(t, "", gameVersionRange(nil))
+	assert.Equal(t, "1.20.1", gameVersionRange([]string{"1.20.1"}))
+	assert.Equal(t, "1.20.1-1.20.4", gameVersionRange([]string{"1.20.4", "1.20.1", "1.20.2"}))
+	assert.Equal(t, "1.19.4-1.20", gameVersionRange([]string{"1.20", "1.19.4", "23w13a"}))
+}
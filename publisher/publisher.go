@@ -0,0 +1,126 @@
+package publisher
+
+import (
+	"context"
+	"fmt"
+	"github.com/mrmelon54/mc-upload-api"
+	"github.com/mrmelon54/mc-upload-api/database"
+	jarparser "github.com/mrmelon54/mc-upload-api/jar-parser"
+	"github.com/mrmelon54/mc-upload-api/storage"
+	"github.com/mrmelon54/mc-upload-api/uploader"
+	"log"
+	"slices"
+)
+
+type Publisher struct {
+	db        *database.Queries
+	storage   storage.Storage
+	uploaders uploader.Registry
+}
+
+func New(db *database.Queries, store storage.Storage, uploaders uploader.Registry) *Publisher {
+	return &Publisher{db, store, uploaders}
+}
+
+// PublishStored uploads a stored build to the named platforms which do not have a
+// remote ID yet, the outcome of every platform is saved even if other platforms fail
+func (p *Publisher) PublishStored(ctx context.Context, project mc_upload_api.Project, build database.Build, platforms []string) (map[string]database.BuildPlatform, error) {
+	existing, err := p.db.ListPlatformsForBuild(ctx, build.ID)
+	if err != nil {
+		return nil, err
+	}
+	results := make(map[string]database.BuildPlatform)
+	for _, i := range existing {
+		if i.RemoteID == "" || !slices.Contains(platforms, i.Platform) {
+			continue
+		}
+		results[i.Platform] = i
+		platforms = slices.DeleteFunc(slices.Clone(platforms), func(s string) bool { return s == i.Platform })
+	}
+	if len(platforms) == 0 {
+		return results, nil
+	}
+
+	file, size, err := storage.OpenFile(ctx, p.storage, build.Sha512)
+	if err != nil {
+		return nil, fmt.Errorf("failed file loading: %w", err)
+	}
+	defer file.Close()
+
+	// dependencies are not stored so the jar is parsed again
+	modMeta, err := jarparser.JarParser(file, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JAR: %w", err)
+	}
+	modMeta.VersionNumber = build.Meta.VersionNumber
+	modMeta.ReleaseChannel = build.Meta.ReleaseChannel
+	modMeta.Loaders = build.Meta.Loaders
+	modMeta.Environment = build.Meta.Environment
+
+	version := uploader.Version{
+		Meta:         modMeta,
+		GameVersions: build.Meta.GameVersions,
+		Name:         build.Meta.Name,
+		Changelog:    build.Changelog,
+		GitCommit:    build.GitCommit,
+		Featured:     build.Meta.Featured,
+		Status:       build.Meta.Status,
+		Filename:     build.Filename,
+		File:         file,
+		Size:         size,
+	}
+	if build.Meta.ScheduledAt != nil {
+		version.ScheduledAt = *build.Meta.ScheduledAt
+	}
+
+	buildFiles, err := p.db.ListFilesForBuild(ctx, build.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range buildFiles {
+		f, size, err := storage.OpenFile(ctx, p.storage, i.Sha512)
+		if err != nil {
+			return nil, fmt.Errorf("failed file loading: %w", err)
+		}
+		defer f.Close()
+		version.Files = append(version.Files, uploader.File{Kind: i.Kind, Filename: i.Filename, File: f, Size: size})
+	}
+	for _, platformName := range platforms {
+		platform := project.Platforms[platformName]
+
+		var res uploader.Result
+		var err error
+		if upld, ok := p.uploaders[platformName]; ok {
+			log.Printf("[Publish] Updating project %s (%s) on %s\n", project.Name, platform.Id, platformName)
+			res, err = upld.UploadVersion(ctx, platform.Target(), version)
+		} else {
+			err = fmt.Errorf("platform is not configured")
+		}
+		if err != nil && ctx.Err() != nil {
+			// the upload was interrupted so the job is retried later
+			return nil, ctx.Err()
+		}
+
+		row := database.SetBuildPlatformParams{
+			BuildID:  build.ID,
+			Platform: platformName,
+			RemoteID: res.Id,
+			Url:      res.Url,
+			Warnings: res.Warnings,
+		}
+		for _, warning := range res.Warnings {
+			log.Printf("[Publish] Warning for project %s on %s: %s\n", project.Name, platformName, warning)
+		}
+		if err != nil {
+			log.Printf("[Publish] Failed to update project %s on %s: %s\n", project.Name, platformName, err)
+			row.Error = err.Error()
+		}
+		// an accepted version is saved even if the job is being stopped so it is
+		// not published again
+		if err := p.db.SetBuildPlatform(context.WithoutCancel(ctx), row); err != nil {
+			return nil, err
+		}
+		results[platformName] = database.BuildPlatform(row)
+	}
+	return results, nil
+}
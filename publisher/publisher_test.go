@@ -0,0 +1,69 @@
+package publisher
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha512"
+	"encoding/hex"
+	"github.com/mrmelon54/mc-upload-api"
+	"github.com/mrmelon54/mc-upload-api/database"
+	"github.com/mrmelon54/mc-upload-api/database/types"
+	"github.com/mrmelon54/mc-upload-api/storage"
+	"github.com/mrmelon54/mc-upload-api/uploader"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+type uploadFunc func(ctx context.Context) (uploader.Result, error)
+
+func (f uploadFunc) UploadVersion(ctx context.Context, project uploader.Project, version uploader.Version) (uploader.Result, error) {
+	return f(ctx)
+}
+
+func (f uploadFunc) ValidateVersion(ctx context.Context, project uploader.Project, version uploader.Version) (uploader.Plan, error) {
+	return uploader.Plan{}, nil
+}
+
+func TestPublishStored_Cancelled(t *testing.T) {
+	db, err := mc_upload_api.InitDB("file:" + t.Name() + "?mode=memory&cache=shared")
+	assert.NoError(t, err)
+	store, err := storage.NewLocal(t.TempDir())
+	assert.NoError(t, err)
+	jar, err := os.ReadFile("../jar-parser/test-fabric.jar")
+	assert.NoError(t, err)
+	hash := sha512.Sum512(jar)
+	sha := hex.EncodeToString(hash[:])
+	assert.NoError(t, store.Put(context.Background(), sha, bytes.NewReader(jar), int64(len(jar))))
+	buildId, err := db.CreateBuild(context.Background(), database.CreateBuildParams{
+		Project: "clock",
+		Meta:    &types.BuildMeta{VersionNumber: "1.0.0", ReleaseChannel: "release"},
+		Sha512:  sha,
+	})
+	assert.NoError(t, err)
+	build, err := db.GetBuildByID(context.Background(), buildId)
+	assert.NoError(t, err)
+
+	// the job is stopped after modrinth accepted the version
+	ctx, cancel := context.WithCancel(context.Background())
+	p := New(db, store, uploader.Registry{
+		"modrinth": uploadFunc(func(ctx context.Context) (uploader.Result, error) {
+			cancel()
+			return uploader.Result{Id: "abcd", Url: "https://modrinth.com/mod/clock/version/abcd"}, nil
+		}),
+		"curseforge": uploadFunc(func(ctx context.Context) (uploader.Result, error) {
+			return uploader.Result{}, ctx.Err()
+		}),
+	})
+	var project mc_upload_api.Project
+	project.Platforms = map[string]mc_upload_api.ProjectPlatform{"modrinth": {Id: "clock"}, "curseforge": {Id: "1234"}}
+
+	_, err = p.PublishStored(ctx, project, build, []string{"modrinth", "curseforge"})
+	assert.ErrorIs(t, err, context.Canceled)
+
+	platforms, err := db.ListPlatformsForBuild(context.Background(), buildId)
+	assert.NoError(t, err)
+	assert.Len(t, platforms, 1)
+	assert.Equal(t, "modrinth", platforms[0].Platform)
+	assert.Equal(t, "abcd", platforms[0].RemoteID)
+}
@@ -0,0 +1,225 @@
+package publisher
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/mrmelon54/mc-upload-api"
+	"github.com/mrmelon54/mc-upload-api/database"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	JobPending = "pending"
+	JobRunning = "running"
+	JobSuccess = "success"
+	JobFailed  = "failed"
+)
+
+const (
+	MaxAttempts  = 8
+	BaseBackoff  = 30 * time.Second
+	MaxBackoff   = time.Hour
+	pollInterval = 5 * time.Second
+
+	updateAttempts   = 6
+	updateRetryDelay = time.Second
+)
+
+// Queue runs publish jobs stored in the database using background workers
+type Queue struct {
+	db          *database.Queries
+	publisher   *Publisher
+	projectsYml *atomic.Pointer[mc_upload_api.ProjectsConfig]
+	wake        chan struct{}
+	wg          sync.WaitGroup
+}
+
+func NewQueue(db *database.Queries, publisher *Publisher, projectsYml *atomic.Pointer[mc_upload_api.ProjectsConfig]) *Queue {
+	return &Queue{
+		db:          db,
+		publisher:   publisher,
+		projectsYml: projectsYml,
+		wake:        make(chan struct{}, 1),
+	}
+}
+
+// Start resets jobs interrupted by a previous shutdown and starts the workers,
+// the workers stop once the context is cancelled
+func (q *Queue) Start(ctx context.Context, workers int) error {
+	if err := q.db.ResetRunningJobs(ctx); err != nil {
+		return err
+	}
+	for range workers {
+		q.wg.Add(1)
+		go q.worker(ctx)
+	}
+	return nil
+}
+
+// Wait blocks until all workers have stopped
+func (q *Queue) Wait() {
+	q.wg.Wait()
+}
+
+// Enqueue adds a job to publish the build, an empty platform publishes to every
+// enabled platform
+func (q *Queue) Enqueue(ctx context.Context, buildId int64, platform string) (int64, error) {
+	jobId, err := CreateJob(ctx, q.db, buildId, platform)
+	if err != nil {
+		return 0, err
+	}
+	q.Wake()
+	return jobId, nil
+}
+
+// CreateJob adds a job without waking the workers, used in transactions which
+// call Wake once committed
+func CreateJob(ctx context.Context, db *database.Queries, buildId int64, platform string) (int64, error) {
+	now := time.Now().Unix()
+	return db.CreateJob(ctx, database.CreateJobParams{
+		BuildID:   buildId,
+		Platform:  platform,
+		NextRun:   now,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+}
+
+// Wake starts a worker checking for ready jobs
+func (q *Queue) Wake() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (q *Queue) worker(ctx context.Context) {
+	defer q.wg.Done()
+	t := time.NewTicker(pollInterval)
+	defer t.Stop()
+	for {
+		// run jobs until there are none ready
+		for q.runNext(ctx) {
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-q.wake:
+		case <-t.C:
+		}
+	}
+}
+
+func (q *Queue) runNext(ctx context.Context) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	now := time.Now().Unix()
+	job, err := q.db.ClaimJob(ctx, database.ClaimJobParams{UpdatedAt: now, NextRun: now})
+	if errors.Is(err, sql.ErrNoRows) {
+		return false
+	} else if err != nil {
+		log.Println("[Queue] Failed to claim job:", err)
+		return false
+	}
+
+	update := database.UpdateJobParams{
+		Status:   JobSuccess,
+		Attempts: job.Attempts + 1,
+		NextRun:  job.NextRun,
+		ID:       job.ID,
+	}
+	if err := q.runJob(ctx, job); err != nil {
+		update.LastError = err.Error()
+		if ctx.Err() != nil {
+			// interrupted uploads do not use up an attempt
+			log.Printf("[Queue] Job %d interrupted: %s\n", job.ID, err)
+			update.Status = JobPending
+			update.Attempts = job.Attempts
+		} else if update.Attempts >= MaxAttempts {
+			log.Printf("[Queue] Job %d failed after %d attempts: %s\n", job.ID, update.Attempts, err)
+			update.Status = JobFailed
+		} else {
+			log.Printf("[Queue] Job %d attempt %d failed: %s\n", job.ID, update.Attempts, err)
+			update.Status = JobPending
+			update.NextRun = time.Now().Add(backoff(update.Attempts)).Unix()
+		}
+	}
+	update.UpdatedAt = time.Now().Unix()
+
+	// the job state is saved even if the queue is stopping
+	if err := q.updateJob(context.WithoutCancel(ctx), update); err != nil {
+		log.Printf("[Queue] Failed to update job %d: %s\n", job.ID, err)
+	}
+	return true
+}
+
+// updateJob retries saving the job state, a job left running is not claimed
+// again until the queue is restarted
+func (q *Queue) updateJob(ctx context.Context, update database.UpdateJobParams) error {
+	var err error
+	for attempt := range updateAttempts {
+		if attempt > 0 {
+			time.Sleep(updateRetryDelay << (attempt - 1))
+		}
+		err = q.db.UpdateJob(ctx, update)
+		if err == nil {
+			return nil
+		}
+		if attempt+1 < updateAttempts {
+			log.Printf("[Queue] Failed to update job %d, retrying: %s\n", update.ID, err)
+		}
+	}
+	return err
+}
+
+func (q *Queue) runJob(ctx context.Context, job database.Job) error {
+	build, err := q.db.GetBuildByID(ctx, job.BuildID)
+	if err != nil {
+		return err
+	}
+	project, ok := (*q.projectsYml.Load())[build.Project]
+	if !ok {
+		return fmt.Errorf("unknown project: %s", build.Project)
+	}
+	platforms := project.EnabledPlatforms()
+	if job.Platform != "" {
+		if !slices.Contains(platforms, job.Platform) {
+			return fmt.Errorf("platform is not enabled: %s", job.Platform)
+		}
+		platforms = []string{job.Platform}
+	}
+
+	results, err := q.publisher.PublishStored(ctx, project, build, platforms)
+	if err != nil {
+		return err
+	}
+	var errs []string
+	for _, platformName := range platforms {
+		if results[platformName].Error != "" {
+			errs = append(errs, platformName+": "+results[platformName].Error)
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// backoff doubles the delay for each failed attempt up to MaxBackoff
+func backoff(attempts int64) time.Duration {
+	if attempts < 1 {
+		return BaseBackoff
+	}
+	if attempts > 20 {
+		return MaxBackoff
+	}
+	return min(BaseBackoff<<(attempts-1), MaxBackoff)
+}
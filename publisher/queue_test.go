@@ -0,0 +1,50 @@
+package publisher
+
+import (
+	"context"
+	"database/sql"
+	"github.com/mrmelon54/mc-upload-api"
+	"github.com/mrmelon54/mc-upload-api/database"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, backoff(1))
+	assert.Equal(t, time.Minute, backoff(2))
+	assert.Equal(t, 2*time.Minute, backoff(3))
+	assert.Equal(t, 32*time.Minute, backoff(7))
+	assert.Equal(t, time.Hour, backoff(8))
+	assert.Equal(t, time.Hour, backoff(100))
+}
+
+func TestClaimJob_OnePerBuild(t *testing.T) {
+	db, err := mc_upload_api.InitDB("file:" + t.Name() + "?mode=memory&cache=shared")
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	q := NewQueue(db, nil, nil)
+	job1, err := q.Enqueue(ctx, 1, "")
+	assert.NoError(t, err)
+	job2, err := q.Enqueue(ctx, 1, "modrinth")
+	assert.NoError(t, err)
+	job3, err := q.Enqueue(ctx, 2, "")
+	assert.NoError(t, err)
+
+	now := time.Now().Unix()
+	claim := database.ClaimJobParams{UpdatedAt: now, NextRun: now}
+	job, err := db.ClaimJob(ctx, claim)
+	assert.NoError(t, err)
+	assert.Equal(t, job1, job.ID)
+	job, err = db.ClaimJob(ctx, claim)
+	assert.NoError(t, err)
+	assert.Equal(t, job3, job.ID)
+	_, err = db.ClaimJob(ctx, claim)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	assert.NoError(t, db.UpdateJob(ctx, database.UpdateJobParams{Status: JobSuccess, Attempts: 1, ID: job1}))
+	job, err = db.ClaimJob(ctx, claim)
+	assert.NoError(t, err)
+	assert.Equal(t, job2, job.ID)
+}
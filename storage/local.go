@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Local stores files in a directory as `<sha512>.jar`
+type Local struct {
+	dir string
+}
+
+var _ Storage = &Local{}
+var _ Lister = &Local{}
+
+// NewLocal creates the directory if it does not exist
+func NewLocal(dir string) (*Local, error) {
+	if err := os.MkdirAll(dir, 0775); err != nil {
+		return nil, err
+	}
+	stat, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !stat.IsDir() {
+		return nil, fmt.Errorf("storage path is not a directory: %s", dir)
+	}
+	return &Local{dir: dir}, nil
+}
+
+func (l *Local) path(sha512 string) (string, error) {
+	name, err := objectName(sha512)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(l.dir, name), nil
+}
+
+// Put writes to a temp file in the directory which is synced and renamed into
+// place so a stored file is never partially written
+func (l *Local) Put(ctx context.Context, sha512 string, r io.Reader, size int64) error {
+	path, err := l.path(sha512)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(l.dir, "put-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+	}()
+	n, err := io.Copy(tmp, r)
+	if err != nil {
+		return err
+	}
+	if n != size {
+		return fmt.Errorf("expected %d bytes but wrote %d", size, n)
+	}
+	if err := tmp.Sync(); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+	return syncDir(l.dir)
+}
+
+// syncDir persists the rename
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+func (l *Local) Get(ctx context.Context, sha512 string) (Object, error) {
+	path, err := l.path(sha512)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return &localObject{f, Info{Size: stat.Size(), ModTime: stat.ModTime()}}, nil
+}
+
+func (l *Local) Stat(ctx context.Context, sha512 string) (Info, error) {
+	path, err := l.path(sha512)
+	if err != nil {
+		return Info{}, err
+	}
+	stat, err := os.Stat(path)
+	if err != nil {
+		return Info{}, err
+	}
+	return Info{Size: stat.Size(), ModTime: stat.ModTime()}, nil
+}
+
+func (l *Local) Delete(ctx context.Context, sha512 string) error {
+	path, err := l.path(sha512)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (l *Local) List(ctx context.Context) ([]string, error) {
+	entries, err := os.ReadDir(l.dir)
+	if err != nil {
+		return nil, err
+	}
+	var a []string
+	for _, i := range entries {
+		if sha512, ok := hashFromName(i.Name()); ok && i.Type().IsRegular() {
+			a = append(a, sha512)
+		}
+	}
+	return a, nil
+}
+
+// localObject can be read at any offset so it is used directly by OpenFile
+type localObject struct {
+	*os.File
+	info Info
+}
+
+func (o *localObject) Info() Info { return o.info }
@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var testHash = strings.Repeat("ab", 64)
+
+func TestLocal(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "builds")
+	l, err := NewLocal(dir)
+	assert.NoError(t, err)
+	assert.False(t, Shared(l))
+	ctx := context.Background()
+
+	_, err = l.Get(ctx, testHash)
+	assert.ErrorIs(t, err, ErrNotExist)
+
+	assert.NoError(t, l.Put(ctx, testHash, bytes.NewReader([]byte("hello world")), 11))
+	assert.FileExists(t, filepath.Join(dir, testHash+".jar"))
+
+	info, err := l.Stat(ctx, testHash)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(11), info.Size)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "put-123.tmp"), nil, 0644))
+	hashes, err := l.List(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{testHash}, hashes)
+	assert.NoError(t, os.Remove(filepath.Join(dir, "put-123.tmp")))
+
+	obj, err := l.Get(ctx, testHash)
+	assert.NoError(t, err)
+	b, err := io.ReadAll(obj)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello world", string(b))
+	assert.NoError(t, obj.Close())
+
+	f, size, err := OpenFile(ctx, l, testHash)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(11), size)
+	assert.IsType(t, &localObject{}, f)
+	assert.NoError(t, f.Close())
+
+	assert.NoError(t, l.Delete(ctx, testHash))
+	assert.NoError(t, l.Delete(ctx, testHash))
+	_, err = l.Stat(ctx, testHash)
+	assert.ErrorIs(t, err, ErrNotExist)
+
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestLocal_Put_ShortWrite(t *testing.T) {
+	l, err := NewLocal(t.TempDir())
+	assert.NoError(t, err)
+	assert.EqualError(t, l.Put(context.Background(), testHash, bytes.NewReader([]byte("abc")), 4), "expected 4 bytes but wrote 3")
+	_, err = l.Stat(context.Background(), testHash)
+	assert.ErrorIs(t, err, ErrNotExist)
+}
+
+func TestObjectName(t *testing.T) {
+	_, err := objectName("../../etc/passwd")
+	assert.ErrorIs(t, err, errInvalidHash)
+	_, err = objectName(strings.Repeat("zz", 64))
+	assert.ErrorIs(t, err, errInvalidHash)
+	name, err := objectName(testHash)
+	assert.NoError(t, err)
+	assert.Equal(t, testHash+".jar", name)
+}
@@ -0,0 +1,369 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type S3Config struct {
+	Endpoint  string `yaml:"endpoint"`
+	Region    string `yaml:"region"`
+	Bucket    string `yaml:"bucket"`
+	AccessKey string `yaml:"accessKey"`
+	SecretKey string `yaml:"secretKey"`
+
+	// Prefix is added to the object keys
+	Prefix string `yaml:"prefix"`
+
+	// PathStyle puts the bucket in the path instead of the host, used by MinIO
+	PathStyle bool `yaml:"pathStyle"`
+}
+
+// S3 stores files in an S3 compatible bucket, requests are signed with AWS
+// signature version 4
+type S3 struct {
+	conf     S3Config
+	endpoint *url.URL
+	client   *http.Client
+	now      func() time.Time
+}
+
+var _ Storage = &S3{}
+var _ Lister = &S3{}
+
+func NewS3(conf S3Config, client *http.Client) (*S3, error) {
+	if conf.Endpoint == "" || conf.Bucket == "" {
+		return nil, errors.New("s3 storage requires an endpoint and bucket")
+	}
+	endpoint, err := url.Parse(conf.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid s3 endpoint: %w", err)
+	}
+	if conf.Region == "" {
+		conf.Region = "us-east-1"
+	}
+	return &S3{conf: conf, endpoint: endpoint, client: client, now: time.Now}, nil
+}
+
+// bucketUrl is the address of the key in the bucket
+func (s *S3) bucketUrl(key string) *url.URL {
+	u := *s.endpoint
+	if s.conf.PathStyle {
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/" + s.conf.Bucket + "/" + key
+	} else {
+		u.Host = s.conf.Bucket + "." + u.Host
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/" + key
+	}
+	return &u
+}
+
+func (s *S3) objectUrl(sha512 string) (*url.URL, error) {
+	name, err := objectName(sha512)
+	if err != nil {
+		return nil, err
+	}
+	return s.bucketUrl(s.conf.Prefix + name), nil
+}
+
+func (s *S3) do(ctx context.Context, method, sha512 string, body io.Reader, size int64, header http.Header) (*http.Response, error) {
+	u, err := s.objectUrl(sha512)
+	if err != nil {
+		return nil, err
+	}
+	return s.send(ctx, method, u, body, size, header)
+}
+
+func (s *S3) send(ctx context.Context, method string, u *url.URL, body io.Reader, size int64, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	payloadHash := emptyPayloadHash
+	if body != nil {
+		req.ContentLength = size
+		payloadHash = "UNSIGNED-PAYLOAD"
+	}
+	s.sign(req, payloadHash)
+	return s.client.Do(req)
+}
+
+func (s *S3) Put(ctx context.Context, sha512 string, r io.Reader, size int64) error {
+	header := http.Header{"Content-Type": {"application/java-archive"}}
+	resp, err := s.do(ctx, http.MethodPut, sha512, r, size, header)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return s3Error(resp)
+	}
+	return nil
+}
+
+func (s *S3) Get(ctx context.Context, sha512 string) (Object, error) {
+	resp, err := s.do(ctx, http.MethodGet, sha512, nil, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, s3Error(resp)
+	}
+	return &s3Object{
+		ctx:    ctx,
+		s:      s,
+		sha512: sha512,
+		info:   s3Info(resp),
+		body:   resp.Body,
+	}, nil
+}
+
+func (s *S3) Stat(ctx context.Context, sha512 string) (Info, error) {
+	resp, err := s.do(ctx, http.MethodHead, sha512, nil, 0, nil)
+	if err != nil {
+		return Info{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Info{}, s3Error(resp)
+	}
+	return s3Info(resp), nil
+}
+
+func (s *S3) Delete(ctx context.Context, sha512 string) error {
+	resp, err := s.do(ctx, http.MethodDelete, sha512, nil, 0, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return s3Error(resp)
+	}
+	return nil
+}
+
+// List pages through the objects under the prefix with ListObjectsV2
+func (s *S3) List(ctx context.Context) ([]string, error) {
+	var a []string
+	var token string
+	for {
+		u := s.bucketUrl("")
+		query := url.Values{"list-type": {"2"}}
+		if s.conf.Prefix != "" {
+			query.Set("prefix", s.conf.Prefix)
+		}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		u.RawQuery = strings.ReplaceAll(query.Encode(), "+", "%20")
+		resp, err := s.send(ctx, http.MethodGet, u, nil, 0, nil)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			err = s3Error(resp)
+			_ = resp.Body.Close()
+			return nil, err
+		}
+		var result struct {
+			Contents []struct {
+				Key string `xml:"Key"`
+			} `xml:"Contents"`
+			IsTruncated           bool   `xml:"IsTruncated"`
+			NextContinuationToken string `xml:"NextContinuationToken"`
+		}
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("s3 list: %w", err)
+		}
+		for _, i := range result.Contents {
+			name, ok := strings.CutPrefix(i.Key, s.conf.Prefix)
+			if !ok || strings.Contains(name, "/") {
+				continue
+			}
+			if sha512, ok := hashFromName(name); ok {
+				a = append(a, sha512)
+			}
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return a, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+func s3Info(resp *http.Response) Info {
+	info := Info{Size: resp.ContentLength}
+	if t, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		info.ModTime = t
+	}
+	return info
+}
+
+// s3Error reads the XML error body, missing objects return ErrNotExist
+func s3Error(resp *http.Response) error {
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotExist
+	}
+	var errData struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	if err := xml.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&errData); err != nil || errData.Code == "" {
+		return fmt.Errorf("s3 remote error: %s", resp.Status)
+	}
+	return fmt.Errorf("s3 remote error: %s -- %s", errData.Code, errData.Message)
+}
+
+// emptyPayloadHash is the sha256 of an empty body
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// signedHeaders are signed in addition to the host and x-amz-* headers
+var signedHeaders = []string{"content-md5", "content-type", "range"}
+
+// sign adds the AWS signature version 4 authorization header
+func (s *S3) sign(req *http.Request, payloadHash string) {
+	t := s.now().UTC()
+	amzDate := t.Format("20060102T150405Z")
+	date := t.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		k = strings.ToLower(k)
+		if strings.HasPrefix(k, "x-amz-") || slices.Contains(signedHeaders, k) {
+			headers[k] = strings.TrimSpace(strings.Join(v, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	slices.Sort(names)
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedNames := strings.Join(names, ";")
+
+	canonicalUri := req.URL.EscapedPath()
+	if canonicalUri == "" {
+		canonicalUri = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalUri,
+		strings.ReplaceAll(req.URL.Query().Encode(), "+", "%20"),
+		canonicalHeaders.String(),
+		signedNames,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.conf.Region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSha256([]byte("AWS4"+s.conf.SecretKey), date)
+	key = hmacSha256(key, s.conf.Region)
+	key = hmacSha256(key, "s3")
+	key = hmacSha256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSha256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", s.conf.AccessKey, scope, signedNames, signature))
+}
+
+func hmacSha256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// s3Object streams the object body, seeking reopens the body with a range
+// request from the new offset when it is read
+type s3Object struct {
+	ctx    context.Context
+	s      *S3
+	sha512 string
+	info   Info
+
+	off     int64
+	body    io.ReadCloser
+	bodyOff int64
+}
+
+func (o *s3Object) Info() Info { return o.info }
+
+func (o *s3Object) Read(p []byte) (int, error) {
+	if o.off >= o.info.Size {
+		return 0, io.EOF
+	}
+	if o.body == nil || o.bodyOff != o.off {
+		if err := o.open(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := o.body.Read(p)
+	o.off += int64(n)
+	o.bodyOff += int64(n)
+	return n, err
+}
+
+func (o *s3Object) open() error {
+	if o.body != nil {
+		_ = o.body.Close()
+		o.body = nil
+	}
+	header := http.Header{"Range": {"bytes=" + strconv.FormatInt(o.off, 10) + "-"}}
+	resp, err := o.s.do(o.ctx, http.MethodGet, o.sha512, nil, 0, header)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusPartialContent {
+		defer resp.Body.Close()
+		return s3Error(resp)
+	}
+	o.body = resp.Body
+	o.bodyOff = o.off
+	return nil
+}
+
+func (o *s3Object) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += o.off
+	case io.SeekEnd:
+		offset += o.info.Size
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+	o.off = offset
+	return offset, nil
+}
+
+func (o *s3Object) Close() error {
+	if o.body == nil {
+		return nil
+	}
+	return o.body.Close()
+}
@@ -0,0 +1,152 @@
+package storage
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrNotExist is returned when no file is stored for the hash
+var ErrNotExist = fs.ErrNotExist
+
+// Storage keeps build artifacts by their sha512 hash
+type Storage interface {
+	// Put stores size bytes from r, an existing file with the hash is replaced
+	Put(ctx context.Context, sha512 string, r io.Reader, size int64) error
+
+	Get(ctx context.Context, sha512 string) (Object, error)
+	Stat(ctx context.Context, sha512 string) (Info, error)
+
+	// Delete does not fail if the file does not exist
+	Delete(ctx context.Context, sha512 string) error
+}
+
+// Lister is implemented by storage which can list the stored hashes
+type Lister interface {
+	List(ctx context.Context) ([]string, error)
+}
+
+// Shared reports whether other instances may use the storage, only local storage
+// belongs to a single instance
+//
+// Files in shared storage can be referenced by builds of other instances so
+// they are never deleted as orphans.
+func Shared(s Storage) bool {
+	_, local := s.(*Local)
+	return !local
+}
+
+// Object is a stored file, it must be closed after use
+type Object interface {
+	io.ReadSeekCloser
+	Info() Info
+}
+
+type Info struct {
+	Size    int64
+	ModTime time.Time
+}
+
+type Config struct {
+	// Type is local or s3, defaults to local
+	Type string `yaml:"type"`
+
+	// Dir is the directory of the local storage, relative paths start from the
+	// config directory, defaults to builds
+	Dir string `yaml:"dir"`
+
+	S3Config `yaml:",inline"`
+}
+
+// New creates the configured storage, wd is the config directory
+func New(conf Config, wd string, client *http.Client) (Storage, error) {
+	switch conf.Type {
+	case "", "local":
+		dir := conf.Dir
+		if dir == "" {
+			dir = "builds"
+		}
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(wd, dir)
+		}
+		return NewLocal(dir)
+	case "s3":
+		return NewS3(conf.S3Config, client)
+	}
+	return nil, fmt.Errorf("unknown storage type: %s", conf.Type)
+}
+
+// File can be read at any offset
+type File interface {
+	io.ReaderAt
+	io.Closer
+}
+
+// OpenFile returns the stored file for random access, objects which can only be
+// streamed are copied to a temp file which is removed on close
+func OpenFile(ctx context.Context, s Storage, sha512 string) (File, int64, error) {
+	obj, err := s.Get(ctx, sha512)
+	if err != nil {
+		return nil, 0, err
+	}
+	if f, ok := obj.(File); ok {
+		return f, obj.Info().Size, nil
+	}
+	defer obj.Close()
+
+	tmp, err := os.CreateTemp("", "storage-*.tmp")
+	if err != nil {
+		return nil, 0, err
+	}
+	n, err := io.Copy(tmp, obj)
+	if err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return nil, 0, err
+	}
+	return tempFile{tmp}, n, nil
+}
+
+type tempFile struct {
+	*os.File
+}
+
+func (t tempFile) Close() error {
+	err := t.File.Close()
+	_ = os.Remove(t.File.Name())
+	return err
+}
+
+var errInvalidHash = errors.New("invalid sha512 hash")
+
+// hashFromName returns the hash of a stored file name
+func hashFromName(name string) (string, bool) {
+	sha512, ok := strings.CutSuffix(name, ".jar")
+	if !ok {
+		return "", false
+	}
+	if _, err := objectName(sha512); err != nil {
+		return "", false
+	}
+	return sha512, true
+}
+
+// objectName is the name of the stored file, the hash is checked so it can be
+// used in paths
+func objectName(sha512 string) (string, error) {
+	if len(sha512) != 128 {
+		return "", errInvalidHash
+	}
+	if _, err := hex.DecodeString(sha512); err != nil {
+		return "", errInvalidHash
+	}
+	return sha512 + ".jar", nil
+}
@@ -0,0 +1,213 @@
+package uploader
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ClientConfig is shared by all platform configs to tune the http client
+type ClientConfig struct {
+	// Timeout applies to each request attempt, defaults to 2 minutes
+	Timeout time.Duration `yaml:"timeout"`
+
+	// MaxRetries defaults to 3, negative values disable retries
+	MaxRetries int `yaml:"maxRetries"`
+}
+
+const (
+	defaultTimeout    = 2 * time.Minute
+	defaultMaxRetries = 3
+	retryBaseDelay    = time.Second
+	retryMaxDelay     = time.Minute
+)
+
+// platformClient retries rate limited and transient failures, waiting for the
+// delay requested by the platform
+type platformClient struct {
+	client     *http.Client
+	timeout    time.Duration
+	maxRetries int
+	sleep      func(ctx context.Context, d time.Duration) error
+
+	limitMu      *sync.Mutex
+	blockedUntil time.Time
+}
+
+func newPlatformClient(client *http.Client, config ClientConfig) *platformClient {
+	c := &platformClient{
+		client:     client,
+		timeout:    config.Timeout,
+		maxRetries: config.MaxRetries,
+		sleep:      sleepContext,
+		limitMu:    new(sync.Mutex),
+	}
+	if c.timeout <= 0 {
+		c.timeout = defaultTimeout
+	}
+	switch {
+	case c.maxRetries == 0:
+		c.maxRetries = defaultMaxRetries
+	case c.maxRetries < 0:
+		c.maxRetries = 0
+	}
+	return c
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// idempotentMethods are safe to retry after a transport error or a bad gateway
+var idempotentMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodOptions: true,
+	http.MethodPut:     true,
+	http.MethodDelete:  true,
+}
+
+// retryableStatus reports whether the request can be sent again, rate limited and
+// unavailable responses were never processed so are safe for all methods
+func retryableStatus(method string, status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return idempotentMethods[method]
+	}
+	return false
+}
+
+func (c *platformClient) Do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	canRewind := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+
+	for attempt := 0; ; attempt++ {
+		if err := c.waitRateLimit(ctx); err != nil {
+			return nil, err
+		}
+
+		attemptCtx, cancel := context.WithTimeout(ctx, c.timeout)
+		attemptReq := req.Clone(attemptCtx)
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				cancel()
+				return nil, err
+			}
+			attemptReq.Body = body
+		}
+
+		canRetry := canRewind && attempt < c.maxRetries && ctx.Err() == nil
+		resp, err := c.client.Do(attemptReq)
+		if err != nil {
+			cancel()
+			if !canRetry || !idempotentMethods[req.Method] {
+				return nil, err
+			}
+			if err := c.sleep(ctx, backoffDelay(attempt)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		c.updateRateLimit(resp.Header)
+		if canRetry && retryableStatus(req.Method, resp.StatusCode) {
+			delay := retryDelay(resp.Header, attempt)
+			if delay <= retryMaxDelay {
+				_, _ = io.Copy(io.Discard, resp.Body)
+				_ = resp.Body.Close()
+				cancel()
+				if err := c.sleep(ctx, delay); err != nil {
+					return nil, err
+				}
+				continue
+			}
+		}
+
+		// the attempt context must live until the caller has read the body
+		resp.Body = &cancelBody{resp.Body, cancel}
+		return resp, nil
+	}
+}
+
+func (c *platformClient) waitRateLimit(ctx context.Context) error {
+	c.limitMu.Lock()
+	wait := time.Until(c.blockedUntil)
+	c.limitMu.Unlock()
+	if wait <= 0 {
+		return nil
+	}
+	return c.sleep(ctx, min(wait, retryMaxDelay))
+}
+
+// updateRateLimit blocks further requests until the rate limit resets once the
+// remaining request count reaches zero
+func (c *platformClient) updateRateLimit(h http.Header) {
+	if h.Get("X-Ratelimit-Remaining") != "0" {
+		return
+	}
+	reset, ok := parseRateLimitReset(h.Get("X-Ratelimit-Reset"))
+	if !ok {
+		return
+	}
+	c.limitMu.Lock()
+	c.blockedUntil = time.Now().Add(min(reset, retryMaxDelay))
+	c.limitMu.Unlock()
+}
+
+// parseRateLimitReset accepts seconds until the reset (Modrinth) or the unix
+// time of the reset (GitHub)
+func parseRateLimitReset(s string) (time.Duration, bool) {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	if n > 1_000_000_000 {
+		return time.Until(time.Unix(n, 0)), true
+	}
+	return time.Duration(n) * time.Second, true
+}
+
+// retryDelay prefers Retry-After, then the rate limit reset and falls back to
+// exponential backoff
+func retryDelay(h http.Header, attempt int) time.Duration {
+	if retryAfter := h.Get("Retry-After"); retryAfter != "" {
+		if n, err := strconv.ParseInt(retryAfter, 10, 64); err == nil && n >= 0 {
+			return time.Duration(n) * time.Second
+		}
+		if t, err := http.ParseTime(retryAfter); err == nil {
+			return max(time.Until(t), 0)
+		}
+	}
+	if reset, ok := parseRateLimitReset(h.Get("X-Ratelimit-Reset")); ok {
+		return max(reset, 0)
+	}
+	return backoffDelay(attempt)
+}
+
+func backoffDelay(attempt int) time.Duration {
+	return min(retryBaseDelay<<attempt, retryMaxDelay)
+}
+
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelBody) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
@@ -0,0 +1,112 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"github.com/mrmelon54/mc-upload-api/uploader/test"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func testPlatformClient(handler *http.ServeMux, sleeps *[]time.Duration) *platformClient {
+	c := newPlatformClient(test.NewTestServer(handler), ClientConfig{})
+	c.sleep = func(ctx context.Context, d time.Duration) error {
+		*sleeps = append(*sleeps, d)
+		return nil
+	}
+	return c
+}
+
+func TestPlatformClient_RetryAfter(t *testing.T) {
+	var calls int
+	var bodies []string
+	r := http.NewServeMux()
+	r.HandleFunc("/version", func(rw http.ResponseWriter, req *http.Request) {
+		calls++
+		b, _ := io.ReadAll(req.Body)
+		bodies = append(bodies, string(b))
+		if calls == 1 {
+			rw.Header().Set("Retry-After", "7")
+			rw.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		rw.WriteHeader(http.StatusOK)
+	})
+	var sleeps []time.Duration
+	c := testPlatformClient(r, &sleeps)
+
+	req, err := http.NewRequest(http.MethodPost, "http://localhost/version", bytes.NewReader([]byte("hello")))
+	assert.NoError(t, err)
+	resp, err := c.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NoError(t, resp.Body.Close())
+	assert.Equal(t, []string{"hello", "hello"}, bodies)
+	assert.Equal(t, []time.Duration{7 * time.Second}, sleeps)
+}
+
+func TestPlatformClient_BadGateway(t *testing.T) {
+	var calls int
+	r := http.NewServeMux()
+	r.HandleFunc("/", func(rw http.ResponseWriter, req *http.Request) {
+		calls++
+		rw.WriteHeader(http.StatusBadGateway)
+	})
+	var sleeps []time.Duration
+	c := testPlatformClient(r, &sleeps)
+
+	// uploads may have been processed so are not retried
+	req, err := http.NewRequest(http.MethodPost, "http://localhost/", bytes.NewReader([]byte("hello")))
+	assert.NoError(t, err)
+	resp, err := c.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	assert.Equal(t, 1, calls)
+
+	calls = 0
+	req, err = http.NewRequest(http.MethodGet, "http://localhost/", nil)
+	assert.NoError(t, err)
+	resp, err = c.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	assert.Equal(t, defaultMaxRetries+1, calls)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second}, sleeps)
+}
+
+func TestPlatformClient_RateLimitReset(t *testing.T) {
+	r := http.NewServeMux()
+	r.HandleFunc("/", func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("X-Ratelimit-Remaining", "0")
+		rw.Header().Set("X-Ratelimit-Reset", "30")
+		rw.WriteHeader(http.StatusOK)
+	})
+	var sleeps []time.Duration
+	c := testPlatformClient(r, &sleeps)
+
+	for range 2 {
+		req, err := http.NewRequest(http.MethodGet, "http://localhost/", nil)
+		assert.NoError(t, err)
+		resp, err := c.Do(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+	assert.Len(t, sleeps, 1)
+	assert.InDelta(t, 30*time.Second, sleeps[0], float64(time.Second))
+}
+
+func TestParseRateLimitReset(t *testing.T) {
+	d, ok := parseRateLimitReset("12")
+	assert.True(t, ok)
+	assert.Equal(t, 12*time.Second, d)
+
+	d, ok = parseRateLimitReset(strconv.FormatInt(time.Now().Add(time.Minute).Unix(), 10))
+	assert.True(t, ok)
+	assert.InDelta(t, time.Minute, d, float64(2*time.Second))
+
+	_, ok = parseRateLimitReset("")
+	assert.False(t, ok)
+}
@@ -1,11 +1,10 @@
 package uploader
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"github.com/mrmelon54/rescheduler"
 	jar_parser "github.com/mrmelon54/mc-upload-api/jar-parser"
 	"io"
 	"log"
@@ -18,15 +17,15 @@ import (
 
 type curseforge struct {
 	conf   CurseforgeConfig
-	client *http.Client
+	client *platformClient
 
 	// version cache
-	r         *rescheduler.Rescheduler
-	cacheMu   *sync.RWMutex
-	expires   time.Time
-	envCache  map[string]int
-	verCache  map[string]int
-	platCache map[string]int
+	refreshLock chan struct{}
+	cacheMu     *sync.RWMutex
+	expires     time.Time
+	envCache    map[string]int
+	verCache    map[string]int
+	platCache   map[string]int
 }
 
 var _ Uploader = &curseforge{}
@@ -36,14 +35,14 @@ func NewCurseforgeUploader(config CurseforgeConfig, client *http.Client) Uploade
 		return &empty{}
 	}
 	c := &curseforge{
-		conf:      config,
-		client:    client,
-		cacheMu:   new(sync.RWMutex),
-		envCache:  make(map[string]int),
-		verCache:  make(map[string]int),
-		platCache: make(map[string]int),
+		conf:        config,
+		client:      newPlatformClient(client, config.ClientConfig),
+		refreshLock: make(chan struct{}, 1),
+		cacheMu:     new(sync.RWMutex),
+		envCache:    make(map[string]int),
+		verCache:    make(map[string]int),
+		platCache:   make(map[string]int),
 	}
-	c.r = rescheduler.NewRescheduler(c.generateCache)
 	return c
 }
 
@@ -51,6 +50,8 @@ type CurseforgeConfig struct {
 	Endpoint  string `yaml:"endpoint"`
 	Token     string `yaml:"token"`
 	UserAgent string `yaml:"userAgent"`
+
+	ClientConfig `yaml:",inline"`
 }
 
 type CfVersionTypes struct {
@@ -59,8 +60,8 @@ type CfVersionTypes struct {
 	Slug string `json:"slug"`
 }
 
-func (c *curseforge) gameVersionTypes() ([]CfVersionTypes, error) {
-	req, err := http.NewRequest(http.MethodGet, c.conf.Endpoint+"/game/version-types", nil)
+func (c *curseforge) gameVersionTypes(ctx context.Context) ([]CfVersionTypes, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.conf.Endpoint+"/game/version-types", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -84,8 +85,8 @@ type CfVersions struct {
 	Slug              string `json:"slug"`
 }
 
-func (c *curseforge) gameVersions() ([]CfVersions, error) {
-	req, err := http.NewRequest(http.MethodGet, c.conf.Endpoint+"/game/versions", nil)
+func (c *curseforge) gameVersions(ctx context.Context) ([]CfVersions, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.conf.Endpoint+"/game/versions", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -105,18 +106,29 @@ func (c *curseforge) gameVersions() ([]CfVersions, error) {
 	return vers, nil
 }
 
-func (c *curseforge) generateCache() {
+// refreshCache fetches the game versions when the cache is close to expiring,
+// only one refresh runs at a time
+func (c *curseforge) refreshCache(ctx context.Context) error {
+	select {
+	case c.refreshLock <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-c.refreshLock }()
+
 	c.cacheMu.RLock()
 	isValid := c.expires.Add(-2 * time.Hour).After(time.Now())
 	c.cacheMu.RUnlock()
 	if isValid {
-		return
+		return nil
 	}
+	return c.generateCache(ctx)
+}
 
-	verTypes, err := c.gameVersionTypes()
+func (c *curseforge) generateCache(ctx context.Context) error {
+	verTypes, err := c.gameVersionTypes(ctx)
 	if err != nil {
-		log.Println("[CF Cache] Failed to fetch game version types:", err)
-		return
+		return fmt.Errorf("failed to fetch game version types: %w", err)
 	}
 
 	var envId, platId int
@@ -134,10 +146,9 @@ func (c *curseforge) generateCache() {
 		}
 	}
 
-	versions, err := c.gameVersions()
+	versions, err := c.gameVersions(ctx)
 	if err != nil {
-		log.Println("[CF Cache] Failed to fetch game versions:", err)
-		return
+		return fmt.Errorf("failed to fetch game versions: %w", err)
 	}
 
 	c.cacheMu.Lock()
@@ -159,15 +170,19 @@ func (c *curseforge) generateCache() {
 	c.platCache = mPlat
 	c.verCache = mVer
 	c.cacheMu.Unlock()
+	return nil
 }
 
 var ErrExpiredCacheData = errors.New("expired cache data")
 
-func (c *curseforge) lookupCfIds(loaders, versions []string, environment string) ([]int, error) {
-	c.r.Run()
-	n := time.Now()
-	c.r.Wait()
-	fmt.Println(time.Since(n))
+func (c *curseforge) lookupCfIds(ctx context.Context, loaders, versions []string, environment string) ([]int, error) {
+	if err := c.refreshCache(ctx); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		// the previous cache is still used until it expires
+		log.Println("[CF Cache]", err)
+	}
 	c.cacheMu.RLock()
 	defer c.cacheMu.RUnlock()
 	if c.expires.Before(time.Now()) {
@@ -200,53 +215,146 @@ func (c *curseforge) lookupCfIds(loaders, versions []string, environment string)
 }
 
 type curseforgeUploadDataStructure struct {
-	Changelog    string `json:"changelog"`
-	GameVersions []int  `json:"gameVersions"`
-	ReleaseType  string `json:"releaseType"`
+	DisplayName   string               `json:"displayName,omitempty"`
+	Changelog     string               `json:"changelog"`
+	ChangelogType string               `json:"changelogType"`
+	GameVersions  []int                `json:"gameVersions"`
+	ReleaseType   string               `json:"releaseType"`
+	Relations     *curseforgeRelations `json:"relations,omitempty"`
 }
 
-func (c *curseforge) UploadVersion(projectId string, meta jar_parser.ModMetadata, versions []string, filename string, fileBody io.Reader) (string, error) {
-	intVersions, err := c.lookupCfIds(meta.Loaders, versions, meta.Environment)
-	if err != nil {
-		return "", fmt.Errorf("invalid game version: %w", err)
-	}
+// curseforgeChildDataStructure is the metadata of an additional file, the game
+// versions are taken from the parent file
+type curseforgeChildDataStructure struct {
+	ParentFileID  int    `json:"parentFileID"`
+	DisplayName   string `json:"displayName,omitempty"`
+	Changelog     string `json:"changelog"`
+	ChangelogType string `json:"changelogType"`
+	ReleaseType   string `json:"releaseType"`
+}
 
-	bodyBuf := new(bytes.Buffer)
-	mpw := multipart.NewWriter(bodyBuf)
+type curseforgeRelations struct {
+	Projects []curseforgeProjectRelation `json:"projects"`
+}
 
-	data := curseforgeUploadDataStructure{
-		Changelog:    "",
-		GameVersions: intVersions,
-		ReleaseType:  meta.ReleaseChannel,
+type curseforgeProjectRelation struct {
+	Slug string `json:"slug"`
+	Type string `json:"type"`
+}
+
+var curseforgeRelationTypes = map[jar_parser.DependencyType]string{
+	jar_parser.DependencyRequired:     "requiredDependency",
+	jar_parser.DependencyOptional:     "optionalDependency",
+	jar_parser.DependencyIncompatible: "incompatible",
+	jar_parser.DependencyEmbedded:     "embeddedLibrary",
+}
+
+func curseforgeProjectRelations(project Project, deps []jar_parser.Dependency) *curseforgeRelations {
+	a := make([]curseforgeProjectRelation, 0, len(deps))
+	for _, i := range deps {
+		slug, ok := project.Dependencies[i.ModId]
+		if !ok {
+			continue
+		}
+		relationType, ok := curseforgeRelationTypes[i.Type]
+		if !ok {
+			continue
+		}
+		a = append(a, curseforgeProjectRelation{
+			Slug: slug,
+			Type: relationType,
+		})
+	}
+	if len(a) == 0 {
+		return nil
 	}
+	return &curseforgeRelations{Projects: a}
+}
 
-	field, err := mpw.CreateFormField("metadata")
+func (c *curseforge) versionData(ctx context.Context, project Project, version Version) (curseforgeUploadDataStructure, error) {
+	intVersions, err := c.lookupCfIds(ctx, version.Meta.Loaders, version.GameVersions, version.Meta.Environment)
 	if err != nil {
-		return "", err
+		return curseforgeUploadDataStructure{}, fmt.Errorf("invalid game version: %w", err)
 	}
-	encoder := json.NewEncoder(field)
-	if err = encoder.Encode(data); err != nil {
-		return "", err
+	return curseforgeUploadDataStructure{
+		DisplayName:   version.Name,
+		Changelog:     version.Changelog,
+		ChangelogType: "markdown",
+		GameVersions:  intVersions,
+		ReleaseType:   version.Meta.ReleaseChannel,
+		Relations:     curseforgeProjectRelations(project, version.Meta.Dependencies),
+	}, nil
+}
+
+func (c *curseforge) ValidateVersion(ctx context.Context, project Project, version Version) (Plan, error) {
+	data, err := c.versionData(ctx, project, version)
+	if err != nil {
+		return Plan{}, err
 	}
+	return Plan{
+		Data:     data,
+		Warnings: unmappedDependencies(project, version.Meta.Dependencies),
+	}, nil
+}
 
-	file, err := mpw.CreateFormFile("file", filename)
+func (c *curseforge) UploadVersion(ctx context.Context, project Project, version Version) (Result, error) {
+	data, err := c.versionData(ctx, project, version)
 	if err != nil {
-		return "", err
+		return Result{}, err
+	}
+	id, err := c.uploadFile(ctx, project, data, version.Filename, version.Reader)
+	if err != nil {
+		return Result{}, err
+	}
+	fileId := fmt.Sprintf("%d", id)
+	res := Result{
+		Id:       fileId,
+		Url:      versionUrl(project, "files", fileId),
+		Warnings: unmappedDependencies(project, version.Meta.Dependencies),
+	}
+
+	// the main file exists now so a failed additional file must not fail the upload
+	for _, i := range version.Files {
+		_, err := c.uploadFile(ctx, project, curseforgeChildDataStructure{
+			ParentFileID:  id,
+			DisplayName:   i.Filename,
+			Changelog:     version.Changelog,
+			ChangelogType: "markdown",
+			ReleaseType:   version.Meta.ReleaseChannel,
+		}, i.Filename, i.Reader)
+		if err != nil {
+			res.Warnings = append(res.Warnings, fmt.Sprintf("failed to upload %s file: %s", i.Kind, err))
+		}
 	}
-	_, _ = io.Copy(file, fileBody)
-	_ = mpw.Close()
+	return res, nil
+}
 
-	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/projects/%s/upload-file", c.conf.Endpoint, projectId), bodyBuf)
+// uploadFile sends a file with the metadata and returns the file ID
+func (c *curseforge) uploadFile(ctx context.Context, project Project, metadata any, filename string, reader func() io.Reader) (int, error) {
+	req, err := newMultipartRequest(ctx, http.MethodPost, fmt.Sprintf("%s/projects/%s/upload-file", c.conf.Endpoint, project.Id), func(mpw *multipart.Writer) error {
+		field, err := mpw.CreateFormField("metadata")
+		if err != nil {
+			return err
+		}
+		if err := json.NewEncoder(field).Encode(metadata); err != nil {
+			return err
+		}
+		file, err := mpw.CreateFormFile("file", filename)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(file, reader())
+		return err
+	})
 	if err != nil {
-		return "", err
+		return 0, err
 	}
 	req.Header.Set("User-Agent", c.conf.UserAgent)
 	req.Header.Set("X-Api-Token", c.conf.Token)
-	req.Header.Add("Content-Type", mpw.FormDataContentType())
 
 	do, err := c.client.Do(req)
 	if err != nil {
-		return "", err
+		return 0, err
 	}
 	defer func(Body io.ReadCloser) {
 		_ = Body.Close()
@@ -254,16 +362,16 @@ func (c *curseforge) UploadVersion(projectId string, meta jar_parser.ModMetadata
 	if do.StatusCode != http.StatusOK {
 		all, err := io.ReadAll(do.Body)
 		if err != nil {
-			return "", err
+			return 0, err
 		}
-		return "", fmt.Errorf("curseforge remote error: %s", string(all))
+		return 0, fmt.Errorf("curseforge remote error: %s", string(all))
 	}
 	var idData struct {
 		Id int `json:"id"`
 	}
 	err = json.NewDecoder(do.Body).Decode(&idData)
 	if err != nil {
-		return "", err
+		return 0, err
 	}
-	return fmt.Sprintf("%d", idData.Id), nil
+	return idData.Id, nil
 }
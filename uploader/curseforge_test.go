@@ -1,10 +1,15 @@
 package uploader
 
 import (
+	"bytes"
+	"context"
 	_ "embed"
 	"encoding/json"
-	"github.com/mrmelon54/rescheduler"
+	"fmt"
+	jar_parser "github.com/mrmelon54/mc-upload-api/jar-parser"
+	"github.com/mrmelon54/mc-upload-api/uploader/test"
 	"github.com/stretchr/testify/assert"
+	"net/http"
 	"strings"
 	"sync"
 	"testing"
@@ -18,11 +23,11 @@ var cfVersionTypesJson []byte
 var cfVersionTypes []byte
 
 //goland:noinspection DuplicatedCode
-func TestLookupCfIds(t *testing.T) {
+func testCurseforgeCache(t *testing.T) *curseforge {
 	c := &curseforge{
-		r:       rescheduler.NewRescheduler(func() {}),
-		cacheMu: new(sync.RWMutex),
-		expires: time.Now().Add(2 * time.Hour),
+		refreshLock: make(chan struct{}, 1),
+		cacheMu:     new(sync.RWMutex),
+		expires:     time.Now().AddDate(0, 0, 1),
 	}
 
 	var verTypes []CfVersionTypes
@@ -62,9 +67,120 @@ func TestLookupCfIds(t *testing.T) {
 	c.envCache = mEnv
 	c.platCache = mPlat
 	c.verCache = mVer
+	return c
+}
 
-	intVersions, err := c.lookupCfIds([]string{"fabric", "quilt", "neoforge"}, []string{"1.20"}, "client")
+func TestLookupCfIds(t *testing.T) {
+	c := testCurseforgeCache(t)
+	intVersions, err := c.lookupCfIds(context.Background(), []string{"fabric", "quilt", "neoforge"}, []string{"1.20"}, "client")
 	assert.NoError(t, err)
 	assert.Len(t, intVersions, 5)
 	assert.EqualValues(t, []int{7499, 9153, 10150, 9971, 9638}, intVersions)
 }
+
+func TestCurseforge_ValidateVersion(t *testing.T) {
+	c := testCurseforgeCache(t)
+	project := Project{Id: "123", Dependencies: map[string]string{"fabric": "fabric-api"}}
+	version := Version{
+		Meta: jar_parser.ModMetadata{
+			VersionNumber:  "1.0.0",
+			ReleaseChannel: "release",
+			Loaders:        []string{"fabric"},
+			Environment:    "client",
+			Dependencies: []jar_parser.Dependency{
+				{ModId: "fabric", Type: jar_parser.DependencyRequired},
+				{ModId: "cloth-config", Type: jar_parser.DependencyRequired},
+			},
+		},
+		GameVersions: []string{"1.20"},
+	}
+
+	plan, err := c.ValidateVersion(context.Background(), project, version)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{7499, 9971, 9638}, plan.Data.(curseforgeUploadDataStructure).GameVersions)
+	assert.Equal(t, []string{"required dependency cloth-config has no project mapping"}, plan.Warnings)
+
+	version.Meta.Loaders = []string{"unknown-loader"}
+	_, err = c.ValidateVersion(context.Background(), project, version)
+	assert.EqualError(t, err, "invalid game version: invalid loader: unknown-loader")
+
+	version.Meta.Loaders = []string{"fabric"}
+	version.GameVersions = []string{"1.99"}
+	_, err = c.ValidateVersion(context.Background(), project, version)
+	assert.EqualError(t, err, "invalid game version: invalid version: 1.99")
+}
+
+func TestCurseforgeProjectRelations(t *testing.T) {
+	project := Project{
+		Id: "123",
+		Dependencies: map[string]string{
+			"fabric":       "fabric-api",
+			"architectury": "architectury-api",
+			"cloth-config": "cloth-config",
+			"sodium":       "sodium",
+		},
+	}
+	relations := curseforgeProjectRelations(project, []jar_parser.Dependency{
+		{ModId: "fabric", Type: jar_parser.DependencyRequired},
+		{ModId: "architectury", Type: jar_parser.DependencyOptional},
+		{ModId: "cloth-config", Type: jar_parser.DependencyEmbedded},
+		{ModId: "sodium", Type: jar_parser.DependencyIncompatible},
+		{ModId: "unknown-mod", Type: jar_parser.DependencyRequired},
+	})
+	assert.Equal(t, &curseforgeRelations{Projects: []curseforgeProjectRelation{
+		{Slug: "fabric-api", Type: "requiredDependency"},
+		{Slug: "architectury-api", Type: "optionalDependency"},
+		{Slug: "cloth-config", Type: "embeddedLibrary"},
+		{Slug: "sodium", Type: "incompatible"},
+	}}, relations)
+
+	assert.Nil(t, curseforgeProjectRelations(Project{Id: "123"}, []jar_parser.Dependency{
+		{ModId: "fabric", Type: jar_parser.DependencyRequired},
+	}))
+}
+
+func TestCurseforge_UploadVersion_AdditionalFiles(t *testing.T) {
+	var metadata []map[string]any
+	r := http.NewServeMux()
+	r.HandleFunc("/projects/123/upload-file", func(rw http.ResponseWriter, req *http.Request) {
+		mpr, err := req.MultipartReader()
+		assert.NoError(t, err)
+		metaPart, err := mpr.NextPart()
+		assert.NoError(t, err)
+		assert.Equal(t, "metadata", metaPart.FormName())
+		var m map[string]any
+		assert.NoError(t, json.NewDecoder(metaPart).Decode(&m))
+		metadata = append(metadata, m)
+
+		filePart, err := mpr.NextPart()
+		assert.NoError(t, err)
+		assert.Equal(t, "file", filePart.FormName())
+
+		rw.WriteHeader(http.StatusOK)
+		_, _ = fmt.Fprintf(rw, `{"id":%d}`, 1000+len(metadata))
+	})
+	srv := test.NewTestServer(r)
+
+	c := testCurseforgeCache(t)
+	c.conf = CurseforgeConfig{Token: "abcd1234"}
+	c.client = newPlatformClient(srv, ClientConfig{})
+	res, err := c.UploadVersion(context.Background(), Project{Id: "123"}, Version{
+		Meta:         jar_parser.ModMetadata{VersionNumber: "1.0.0", ReleaseChannel: "release", Loaders: []string{"fabric"}, Environment: "client"},
+		GameVersions: []string{"1.20"},
+		Filename:     "test-1.0.0.jar",
+		File:         bytes.NewReader([]byte{0x54, 0x54}),
+		Size:         2,
+		Files: []File{
+			{Kind: "sources", Filename: "test-1.0.0-sources.jar", File: bytes.NewReader([]byte{0x55}), Size: 1},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "1001", res.Id)
+	assert.Empty(t, res.Warnings)
+	if assert.Len(t, metadata, 2) {
+		assert.NotContains(t, metadata[0], "parentFileID")
+		assert.EqualValues(t, 1001, metadata[1]["parentFileID"])
+		assert.NotContains(t, metadata[1], "gameVersions")
+		assert.Equal(t, "test-1.0.0-sources.jar", metadata[1]["displayName"])
+	}
+}
@@ -1,14 +1,22 @@
 package uploader
 
 import (
-	jarParser "github.com/mrmelon54/mc-upload-api/jar-parser"
-	"io"
+	"context"
+	"errors"
 )
 
+// ErrNotConfigured is returned by platforms without a config so a publish is
+// not recorded as a success
+var ErrNotConfigured = errors.New("platform is not configured")
+
 type empty struct{}
 
-func (e *empty) UploadVersion(projectId string, meta jarParser.ModMetadata, versions []string, filename string, fileBody io.Reader) (string, error) {
-	return "", nil
+func (e *empty) UploadVersion(ctx context.Context, project Project, version Version) (Result, error) {
+	return Result{}, ErrNotConfigured
+}
+
+func (e *empty) ValidateVersion(ctx context.Context, project Project, version Version) (Plan, error) {
+	return Plan{}, ErrNotConfigured
 }
 
 var _ Uploader = &empty{}
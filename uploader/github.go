@@ -0,0 +1,230 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+type github struct {
+	conf   GithubConfig
+	client *platformClient
+}
+
+var _ Uploader = &github{}
+
+func NewGithubUploader(config GithubConfig, client *http.Client) Uploader {
+	if config == (GithubConfig{}) {
+		return &empty{}
+	}
+	if config.Endpoint == "" {
+		config.Endpoint = "https://api.github.com"
+	}
+	return &github{config, newPlatformClient(client, config.ClientConfig)}
+}
+
+type GithubConfig struct {
+	Endpoint  string `yaml:"endpoint"`
+	Token     string `yaml:"token"`
+	UserAgent string `yaml:"userAgent"`
+
+	ClientConfig `yaml:",inline"`
+}
+
+type githubRelease struct {
+	Id        int64  `json:"id"`
+	UploadUrl string `json:"upload_url"`
+}
+
+type githubCreateReleaseStructure struct {
+	TagName string `json:"tag_name"`
+
+	// TargetCommitish is the commit a new tag is created from, github uses the
+	// default branch when empty
+	TargetCommitish string `json:"target_commitish,omitempty"`
+	Name            string `json:"name"`
+	Body            string `json:"body"`
+	Prerelease      bool   `json:"prerelease"`
+}
+
+type githubErrorStructure struct {
+	Message string `json:"message"`
+	Errors  []struct {
+		Code string `json:"code"`
+	} `json:"errors"`
+}
+
+// githubRepo accepts either "owner/repo" or a repository URL
+func githubRepo(s string) string {
+	s = strings.TrimPrefix(s, "https://")
+	s = strings.TrimPrefix(s, "http://")
+	s = strings.TrimPrefix(s, "github.com/")
+	s = strings.TrimSuffix(s, "/")
+	s = strings.TrimSuffix(s, ".git")
+	return s
+}
+
+func (g *github) newRequest(ctx context.Context, method, u string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", g.conf.UserAgent)
+	req.Header.Set("Authorization", "Bearer "+g.conf.Token)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	return req, nil
+}
+
+func githubRemoteError(resp *http.Response) error {
+	var errData githubErrorStructure
+	if err := json.NewDecoder(resp.Body).Decode(&errData); err != nil {
+		return fmt.Errorf("github remote error: %s", resp.Status)
+	}
+	for _, i := range errData.Errors {
+		if i.Code == "already_exists" {
+			return fmt.Errorf("github remote error: %s -- %s: %w", resp.Status, errData.Message, errGithubAlreadyExists)
+		}
+	}
+	return fmt.Errorf("github remote error: %s -- %s", resp.Status, errData.Message)
+}
+
+var (
+	errGithubReleaseNotFound = errors.New("github release not found")
+	errGithubAlreadyExists   = errors.New("already exists")
+)
+
+func (g *github) findRelease(ctx context.Context, repo, tag string) (githubRelease, error) {
+	req, err := g.newRequest(ctx, http.MethodGet, fmt.Sprintf("%s/repos/%s/releases/tags/%s", g.conf.Endpoint, repo, url.PathEscape(tag)), nil)
+	if err != nil {
+		return githubRelease{}, err
+	}
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return githubRelease{}, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return githubRelease{}, errGithubReleaseNotFound
+	default:
+		return githubRelease{}, githubRemoteError(resp)
+	}
+	var release githubRelease
+	err = json.NewDecoder(resp.Body).Decode(&release)
+	return release, err
+}
+
+func githubReleaseData(version Version) githubCreateReleaseStructure {
+	data := githubCreateReleaseStructure{
+		TagName:         version.Meta.VersionNumber,
+		TargetCommitish: version.GitCommit,
+		Name:            version.Meta.VersionNumber,
+		Body:            version.Changelog,
+		Prerelease:      version.Meta.ReleaseChannel != "release",
+	}
+	if version.Name != "" {
+		data.Name = version.Name
+	}
+	return data
+}
+
+func (g *github) createRelease(ctx context.Context, repo string, version Version) (githubRelease, error) {
+	bodyBuf := new(bytes.Buffer)
+	err := json.NewEncoder(bodyBuf).Encode(githubReleaseData(version))
+	if err != nil {
+		return githubRelease{}, err
+	}
+	req, err := g.newRequest(ctx, http.MethodPost, fmt.Sprintf("%s/repos/%s/releases", g.conf.Endpoint, repo), bodyBuf)
+	if err != nil {
+		return githubRelease{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return githubRelease{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return githubRelease{}, githubRemoteError(resp)
+	}
+	var release githubRelease
+	err = json.NewDecoder(resp.Body).Decode(&release)
+	return release, err
+}
+
+type githubPlanStructure struct {
+	Repo    string                       `json:"repo"`
+	Release githubCreateReleaseStructure `json:"release"`
+	Asset   string                       `json:"asset"`
+}
+
+func (g *github) ValidateVersion(ctx context.Context, project Project, version Version) (Plan, error) {
+	return Plan{
+		Data: githubPlanStructure{
+			Repo:    githubRepo(project.Id),
+			Release: githubReleaseData(version),
+			Asset:   version.Filename,
+		},
+	}, nil
+}
+
+func (g *github) UploadVersion(ctx context.Context, project Project, version Version) (Result, error) {
+	repo := githubRepo(project.Id)
+	release, err := g.findRelease(ctx, repo, version.Meta.VersionNumber)
+	if errors.Is(err, errGithubReleaseNotFound) {
+		release, err = g.createRelease(ctx, repo, version)
+		if errors.Is(err, errGithubAlreadyExists) {
+			// another upload of this version created the release first
+			release, err = g.findRelease(ctx, repo, version.Meta.VersionNumber)
+		}
+	}
+	if err != nil {
+		return Result{}, err
+	}
+
+	// the upload url is a hypermedia template: https://uploads.github.com/repos/o/r/releases/1/assets{?name,label}
+	uploadUrl, _, _ := strings.Cut(release.UploadUrl, "{")
+	if uploadUrl == "" {
+		return Result{}, fmt.Errorf("github release %d is missing an upload url", release.Id)
+	}
+
+	req, err := g.newRequest(ctx, http.MethodPost, uploadUrl+"?name="+url.QueryEscape(version.Filename), version.Reader())
+	if err != nil {
+		return Result{}, err
+	}
+	// github requires a known content length for release assets
+	req.ContentLength = version.Size
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(version.Reader()), nil
+	}
+	req.Header.Set("Content-Type", "application/java-archive")
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return Result{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return Result{}, githubRemoteError(resp)
+	}
+	var assetData struct {
+		Id                 int64  `json:"id"`
+		BrowserDownloadUrl string `json:"browser_download_url"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&assetData)
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{
+		Id:  strconv.FormatInt(assetData.Id, 10),
+		Url: assetData.BrowserDownloadUrl,
+	}, nil
+}
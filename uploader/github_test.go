@@ -0,0 +1,105 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	jar_parser "github.com/mrmelon54/mc-upload-api/jar-parser"
+	"github.com/mrmelon54/mc-upload-api/uploader/test"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestGithubRepo(t *testing.T) {
+	assert.Equal(t, "mrmelon54/clock_hud", githubRepo("mrmelon54/clock_hud"))
+	assert.Equal(t, "mrmelon54/clock_hud", githubRepo("https://github.com/mrmelon54/clock_hud"))
+	assert.Equal(t, "mrmelon54/clock_hud", githubRepo("https://github.com/mrmelon54/clock_hud.git"))
+}
+
+func TestGithub_UploadVersion(t *testing.T) {
+	releases := make(map[string]int64)
+
+	r := http.NewServeMux()
+	r.HandleFunc("GET /repos/mrmelon54/clock_hud/releases/tags/{tag}", func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "Bearer abcd1234", req.Header.Get("Authorization"))
+		id, ok := releases[req.PathValue("tag")]
+		if !ok {
+			rw.WriteHeader(http.StatusNotFound)
+			rw.Write([]byte(`{"message":"Not Found"}`))
+			return
+		}
+		rw.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(rw).Encode(githubRelease{Id: id, UploadUrl: "https://uploads.example.com/repos/mrmelon54/clock_hud/releases/10/assets{?name,label}"})
+	})
+	r.HandleFunc("POST /repos/mrmelon54/clock_hud/releases", func(rw http.ResponseWriter, req *http.Request) {
+		var data githubCreateReleaseStructure
+		assert.NoError(t, json.NewDecoder(req.Body).Decode(&data))
+		assert.Equal(t, "- Fixed a bug", data.Body)
+		assert.Equal(t, "abc1234", data.TargetCommitish)
+		assert.True(t, data.Prerelease)
+		if data.TagName == "1.1.0" {
+			// a concurrent upload created the release after it was not found
+			releases[data.TagName] = 10
+			rw.WriteHeader(http.StatusUnprocessableEntity)
+			rw.Write([]byte(`{"message":"Validation Failed","errors":[{"resource":"Release","code":"already_exists","field":"tag_name"}]}`))
+			return
+		}
+		assert.Equal(t, "1.0.0", data.TagName)
+		releases[data.TagName] = 10
+		rw.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(rw).Encode(githubRelease{Id: 10, UploadUrl: "https://uploads.example.com/repos/mrmelon54/clock_hud/releases/10/assets{?name,label}"})
+	})
+	assetId := int64(500)
+	r.HandleFunc("POST /repos/mrmelon54/clock_hud/releases/10/assets", func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "uploads.example.com", req.Host)
+		assert.Equal(t, "application/java-archive", req.Header.Get("Content-Type"))
+		assert.EqualValues(t, 2, req.ContentLength)
+		body, err := io.ReadAll(req.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte{0x54, 0x54}, body)
+		assetId++
+		rw.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(rw).Encode(map[string]any{
+			"id":                   assetId,
+			"name":                 req.URL.Query().Get("name"),
+			"browser_download_url": "https://github.com/mrmelon54/clock_hud/releases/download/1.0.0/" + req.URL.Query().Get("name"),
+		})
+	})
+	srv := test.NewTestServer(r)
+
+	g := NewGithubUploader(GithubConfig{Endpoint: "http://localhost:7777", Token: "abcd1234"}, srv)
+	version := func(versionNumber, filename string) Version {
+		return Version{
+			Meta: jar_parser.ModMetadata{
+				VersionNumber:  versionNumber,
+				ReleaseChannel: "beta",
+				Loaders:        []string{"fabric"},
+			},
+			GameVersions: []string{"1.20.1"},
+			Changelog:    "- Fixed a bug",
+			GitCommit:    "abc1234",
+			Filename:     filename,
+			File:         bytes.NewReader([]byte{0x54, 0x54}),
+			Size:         2,
+		}
+	}
+
+	ghId, err := g.UploadVersion(context.Background(), Project{Id: "https://github.com/mrmelon54/clock_hud"}, version("1.0.0", "clock-hud-fabric.jar"))
+	assert.NoError(t, err)
+	assert.Equal(t, "501", ghId.Id)
+	assert.Equal(t, "https://github.com/mrmelon54/clock_hud/releases/download/1.0.0/clock-hud-fabric.jar", ghId.Url)
+
+	// the second upload reuses the existing release
+	ghId, err = g.UploadVersion(context.Background(), Project{Id: "mrmelon54/clock_hud"}, version("1.0.0", "clock-hud-forge.jar"))
+	assert.NoError(t, err)
+	assert.Equal(t, "502", ghId.Id)
+	assert.Len(t, releases, 1)
+
+	// the release is found again when creating it races another upload
+	ghId, err = g.UploadVersion(context.Background(), Project{Id: "mrmelon54/clock_hud"}, version("1.1.0", "clock-hud-quilt.jar"))
+	assert.NoError(t, err)
+	assert.Equal(t, "503", ghId.Id)
+	assert.Len(t, releases, 2)
+}
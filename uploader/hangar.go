@@ -0,0 +1,244 @@
+package uploader
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	jar_parser "github.com/mrmelon54/mc-upload-api/jar-parser"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+type hangar struct {
+	conf   HangarConfig
+	client *platformClient
+
+	// jwt cache
+	jwtMu      *sync.Mutex
+	jwt        string
+	jwtExpires time.Time
+}
+
+var _ Uploader = &hangar{}
+
+func NewHangarUploader(config HangarConfig, client *http.Client) Uploader {
+	if config == (HangarConfig{}) {
+		return &empty{}
+	}
+	if config.Endpoint == "" {
+		config.Endpoint = "https://hangar.papermc.io/api/v1"
+	}
+	return &hangar{
+		conf:   config,
+		client: newPlatformClient(client, config.ClientConfig),
+		jwtMu:  new(sync.Mutex),
+	}
+}
+
+type HangarConfig struct {
+	Endpoint  string `yaml:"endpoint"`
+	ApiKey    string `yaml:"apiKey"`
+	UserAgent string `yaml:"userAgent"`
+
+	ClientConfig `yaml:",inline"`
+}
+
+// hangarPlatforms maps loaders to the hangar platform names
+var hangarPlatforms = map[string]string{
+	"paper":     "PAPER",
+	"velocity":  "VELOCITY",
+	"waterfall": "WATERFALL",
+}
+
+type hangarAuthentication struct {
+	Token     string `json:"token"`
+	ExpiresIn int64  `json:"expiresIn"`
+}
+
+// authenticate exchanges the api key for a jwt, the jwt is reused until shortly
+// before it expires
+func (h *hangar) authenticate(ctx context.Context) (string, error) {
+	h.jwtMu.Lock()
+	defer h.jwtMu.Unlock()
+	if h.jwt != "" && h.jwtExpires.After(time.Now().Add(time.Minute)) {
+		return h.jwt, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/authenticate?apiKey=%s", h.conf.Endpoint, url.QueryEscape(h.conf.ApiKey)), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", h.conf.UserAgent)
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", hangarRemoteError(resp)
+	}
+	var auth hangarAuthentication
+	if err := json.NewDecoder(resp.Body).Decode(&auth); err != nil {
+		return "", err
+	}
+	h.jwt = auth.Token
+	h.jwtExpires = time.Now().Add(time.Duration(auth.ExpiresIn) * time.Millisecond)
+	return h.jwt, nil
+}
+
+type hangarUploadDataStructure struct {
+	Version              string                              `json:"version"`
+	PluginDependencies   map[string][]hangarPluginDependency `json:"pluginDependencies"`
+	PlatformDependencies map[string][]string                 `json:"platformDependencies"`
+	Description          string                              `json:"description"`
+	Files                []hangarFile                        `json:"files"`
+	Channel              string                              `json:"channel"`
+}
+
+type hangarPluginDependency struct {
+	Name     string `json:"name"`
+	Required bool   `json:"required"`
+}
+
+type hangarFile struct {
+	Platforms []string `json:"platforms"`
+}
+
+func hangarRemoteError(resp *http.Response) error {
+	all, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("hangar remote error: %s -- %s", resp.Status, string(all))
+}
+
+// hangarChannel finds the hangar channel for a release channel, unless configured
+// otherwise releases go to "Release" and everything else goes to "Snapshot"
+func hangarChannel(project Project, releaseChannel string) string {
+	if channel, ok := project.Channels[releaseChannel]; ok {
+		return channel
+	}
+	if releaseChannel == "release" {
+		return "Release"
+	}
+	return "Snapshot"
+}
+
+func hangarVersionData(project Project, version Version) (hangarUploadDataStructure, error) {
+	var platforms []string
+	platformDeps := make(map[string][]string)
+	pluginDeps := make(map[string][]hangarPluginDependency)
+	for _, loader := range version.Meta.Loaders {
+		platform, ok := hangarPlatforms[loader]
+		if !ok {
+			continue
+		}
+		platforms = append(platforms, platform)
+
+		// proxy platforms are not versioned like the game so are read from the project
+		if platform == "PAPER" {
+			platformDeps[platform] = version.GameVersions
+		} else {
+			platformDeps[platform] = project.PlatformVersions[platform]
+		}
+		if len(platformDeps[platform]) == 0 {
+			return hangarUploadDataStructure{}, fmt.Errorf("no platform versions for %s", platform)
+		}
+
+		pluginDeps[platform] = make([]hangarPluginDependency, 0)
+		for _, dep := range version.Meta.Dependencies {
+			name, ok := project.Dependencies[dep.ModId]
+			if !ok || (dep.Type != jar_parser.DependencyRequired && dep.Type != jar_parser.DependencyOptional) {
+				continue
+			}
+			pluginDeps[platform] = append(pluginDeps[platform], hangarPluginDependency{
+				Name:     name,
+				Required: dep.Type == jar_parser.DependencyRequired,
+			})
+		}
+	}
+	if len(platforms) == 0 {
+		return hangarUploadDataStructure{}, fmt.Errorf("no hangar platforms for loaders: %s", strings.Join(version.Meta.Loaders, ", "))
+	}
+
+	return hangarUploadDataStructure{
+		Version:              version.Meta.VersionNumber,
+		PluginDependencies:   pluginDeps,
+		PlatformDependencies: platformDeps,
+		Description:          version.Changelog,
+		Files:                []hangarFile{{Platforms: platforms}},
+		Channel:              hangarChannel(project, version.Meta.ReleaseChannel),
+	}, nil
+}
+
+func (h *hangar) ValidateVersion(ctx context.Context, project Project, version Version) (Plan, error) {
+	data, err := hangarVersionData(project, version)
+	if err != nil {
+		return Plan{}, err
+	}
+	return Plan{
+		Data:     data,
+		Warnings: unmappedDependencies(project, version.Meta.Dependencies),
+	}, nil
+}
+
+func (h *hangar) UploadVersion(ctx context.Context, project Project, version Version) (Result, error) {
+	data, err := hangarVersionData(project, version)
+	if err != nil {
+		return Result{}, err
+	}
+
+	jwt, err := h.authenticate(ctx)
+	if err != nil {
+		return Result{}, fmt.Errorf("hangar authentication: %w", err)
+	}
+
+	req, err := newMultipartRequest(ctx, http.MethodPost, fmt.Sprintf("%s/projects/%s/upload", h.conf.Endpoint, url.PathEscape(project.Id)), func(mpw *multipart.Writer) error {
+		// hangar requires the version data part to declare a json content type
+		fieldHeader := make(textproto.MIMEHeader)
+		fieldHeader.Set("Content-Disposition", `form-data; name="versionUpload"`)
+		fieldHeader.Set("Content-Type", "application/json")
+		field, err := mpw.CreatePart(fieldHeader)
+		if err != nil {
+			return err
+		}
+		if err := json.NewEncoder(field).Encode(data); err != nil {
+			return err
+		}
+		file, err := mpw.CreateFormFile("files", version.Filename)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(file, version.Reader())
+		return err
+	})
+	if err != nil {
+		return Result{}, err
+	}
+	req.Header.Set("User-Agent", h.conf.UserAgent)
+	req.Header.Set("Authorization", jwt)
+
+	do, err := h.client.Do(req)
+	if err != nil {
+		return Result{}, err
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(do.Body)
+	if do.StatusCode != http.StatusOK {
+		return Result{}, hangarRemoteError(do)
+	}
+
+	// hangar versions are identified by their name within the project
+	return Result{
+		Id:       version.Meta.VersionNumber,
+		Url:      versionUrl(project, "versions", version.Meta.VersionNumber),
+		Warnings: unmappedDependencies(project, version.Meta.Dependencies),
+	}, nil
+}
@@ -0,0 +1,107 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	jar_parser "github.com/mrmelon54/mc-upload-api/jar-parser"
+	"github.com/mrmelon54/mc-upload-api/uploader/test"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestHangar_UploadVersion(t *testing.T) {
+	authCount := 0
+	uploads := make([]hangarUploadDataStructure, 0)
+
+	r := http.NewServeMux()
+	r.HandleFunc("POST /authenticate", func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "abcd1234", req.URL.Query().Get("apiKey"))
+		authCount++
+		rw.WriteHeader(http.StatusOK)
+		rw.Write([]byte(`{"token":"jwt-token","expiresIn":600000}`))
+	})
+	r.HandleFunc("POST /projects/TestPlugin/upload", func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "jwt-token", req.Header.Get("Authorization"))
+		mpr, err := req.MultipartReader()
+		assert.NoError(t, err)
+
+		dataPart, err := mpr.NextPart()
+		assert.NoError(t, err)
+		assert.Equal(t, "versionUpload", dataPart.FormName())
+		assert.Equal(t, "application/json", dataPart.Header.Get("Content-Type"))
+
+		var data hangarUploadDataStructure
+		assert.NoError(t, json.NewDecoder(dataPart).Decode(&data))
+		uploads = append(uploads, data)
+
+		filePart, err := mpr.NextPart()
+		assert.NoError(t, err)
+		assert.Equal(t, "files", filePart.FormName())
+
+		rw.WriteHeader(http.StatusOK)
+		rw.Write([]byte(`{"url":"https://hangar.papermc.io/mrmelon54/TestPlugin/versions/1.0.0"}`))
+	})
+	srv := test.NewTestServer(r)
+
+	h := NewHangarUploader(HangarConfig{Endpoint: "http://localhost:8888", ApiKey: "abcd1234"}, srv)
+	project := Project{
+		Id:               "TestPlugin",
+		Url:              "https://hangar.papermc.io/mrmelon54/TestPlugin",
+		Dependencies:     map[string]string{"Vault": "Vault"},
+		Channels:         map[string]string{"beta": "Beta"},
+		PlatformVersions: map[string][]string{"VELOCITY": {"3.3"}},
+	}
+
+	hangarId, err := h.UploadVersion(context.Background(), project, Version{
+		Meta: jar_parser.ModMetadata{
+			VersionNumber:  "1.0.0",
+			ReleaseChannel: "beta",
+			Loaders:        []string{"paper"},
+			Dependencies: []jar_parser.Dependency{
+				{ModId: "Vault", Type: jar_parser.DependencyRequired},
+				{ModId: "PlaceholderAPI", Type: jar_parser.DependencyOptional},
+			},
+		},
+		GameVersions: []string{"1.20", "1.20.1"},
+		Changelog:    "- Fixed a bug",
+		Filename:     "test-plugin.jar",
+		File:         bytes.NewReader([]byte{0x54, 0x54}),
+		Size:         2,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "1.0.0", hangarId.Id)
+	assert.Equal(t, "https://hangar.papermc.io/mrmelon54/TestPlugin/versions/1.0.0", hangarId.Url)
+	assert.Empty(t, hangarId.Warnings)
+
+	_, err = h.UploadVersion(context.Background(), project, Version{
+		Meta: jar_parser.ModMetadata{
+			VersionNumber:  "1.0.1",
+			ReleaseChannel: "alpha",
+			Loaders:        []string{"velocity"},
+		},
+		Filename: "test-plugin.jar",
+		File:     bytes.NewReader([]byte{0x54, 0x54}),
+		Size:     2,
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, authCount)
+	assert.Len(t, uploads, 2)
+	assert.Equal(t, "Beta", uploads[0].Channel)
+	assert.Equal(t, "- Fixed a bug", uploads[0].Description)
+	assert.Equal(t, map[string][]string{"PAPER": {"1.20", "1.20.1"}}, uploads[0].PlatformDependencies)
+	assert.Equal(t, map[string][]hangarPluginDependency{"PAPER": {{Name: "Vault", Required: true}}}, uploads[0].PluginDependencies)
+	assert.Equal(t, []hangarFile{{Platforms: []string{"PAPER"}}}, uploads[0].Files)
+	assert.Equal(t, "Snapshot", uploads[1].Channel)
+	assert.Equal(t, map[string][]string{"VELOCITY": {"3.3"}}, uploads[1].PlatformDependencies)
+
+	_, err = h.UploadVersion(context.Background(), project, Version{
+		Meta: jar_parser.ModMetadata{
+			VersionNumber: "1.0.2",
+			Loaders:       []string{"fabric"},
+		},
+	})
+	assert.Error(t, err)
+}
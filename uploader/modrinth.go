@@ -2,17 +2,20 @@ package uploader
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	jar_parser "github.com/mrmelon54/mc-upload-api/jar-parser"
 	"io"
 	"mime/multipart"
 	"net/http"
+	"net/url"
+	"time"
 )
 
 type modrinth struct {
 	conf   ModrinthConfig
-	client *http.Client
+	client *platformClient
 }
 
 var _ Uploader = &modrinth{}
@@ -21,26 +24,62 @@ func NewModrinthUploader(config ModrinthConfig, client *http.Client) Uploader {
 	if config == (ModrinthConfig{}) {
 		return &empty{}
 	}
-	return &modrinth{config, client}
+	return &modrinth{config, newPlatformClient(client, config.ClientConfig)}
 }
 
 type ModrinthConfig struct {
 	Endpoint  string `yaml:"endpoint"`
 	Token     string `yaml:"token"`
 	UserAgent string `yaml:"userAgent"`
+
+	ClientConfig `yaml:",inline"`
 }
 
 type modrinthUploadDataStructure struct {
-	Name           string   `json:"name"`
-	VersionNumber  string   `json:"version_number"`
-	VersionBody    *string  `json:"version_body"`
-	Dependencies   any      `json:"dependencies"`
-	GameVersions   []string `json:"game_versions"`
-	ReleaseChannel string   `json:"release_channel"`
-	Loaders        []string `json:"loaders"`
-	Featured       bool     `json:"featured"`
-	ProjectId      string   `json:"project_id"`
-	FileParts      []string `json:"file_parts"`
+	Name            string               `json:"name"`
+	VersionNumber   string               `json:"version_number"`
+	VersionBody     *string              `json:"version_body"`
+	Dependencies    []modrinthDependency `json:"dependencies"`
+	GameVersions    []string             `json:"game_versions"`
+	ReleaseChannel  string               `json:"release_channel"`
+	Loaders         []string             `json:"loaders"`
+	Featured        bool                 `json:"featured"`
+	Status          string               `json:"status,omitempty"`
+	RequestedStatus string               `json:"requested_status,omitempty"`
+	ProjectId       string               `json:"project_id"`
+	FileParts       []string             `json:"file_parts"`
+	PrimaryFile     string               `json:"primary_file"`
+}
+
+type modrinthScheduleStructure struct {
+	Time            time.Time `json:"time"`
+	RequestedStatus string    `json:"requested_status"`
+}
+
+// modrinthPlanStructure is the version data with the optional release schedule
+type modrinthPlanStructure struct {
+	modrinthUploadDataStructure
+	Schedule *modrinthScheduleStructure `json:"schedule,omitempty"`
+}
+
+type modrinthDependency struct {
+	ProjectId      string `json:"project_id"`
+	DependencyType string `json:"dependency_type"`
+}
+
+func modrinthDependencies(project Project, deps []jar_parser.Dependency) []modrinthDependency {
+	a := make([]modrinthDependency, 0, len(deps))
+	for _, i := range deps {
+		projectId, ok := project.Dependencies[i.ModId]
+		if !ok {
+			continue
+		}
+		a = append(a, modrinthDependency{
+			ProjectId:      projectId,
+			DependencyType: string(i.Type),
+		})
+	}
+	return a
 }
 
 type modrinthUploadDataError struct {
@@ -48,50 +87,131 @@ type modrinthUploadDataError struct {
 	Description string `json:"description"`
 }
 
-func (m *modrinth) UploadVersion(projectId string, meta jar_parser.ModMetadata, versions []string, filename string, fileBody io.Reader) (string, error) {
-	bodyBuf := new(bytes.Buffer)
-	mpw := multipart.NewWriter(bodyBuf)
-
+func modrinthVersionData(project Project, version Version) modrinthPlanStructure {
 	data := modrinthUploadDataStructure{
-		Name:           filename,
-		VersionNumber:  meta.VersionNumber,
+		Name:           version.Filename,
+		VersionNumber:  version.Meta.VersionNumber,
 		VersionBody:    nil,
-		Dependencies:   []string{},
-		GameVersions:   versions,
-		ReleaseChannel: meta.ReleaseChannel,
-		Loaders:        meta.Loaders,
-		Featured:       false,
-		ProjectId:      projectId,
+		Dependencies:   modrinthDependencies(project, version.Meta.Dependencies),
+		GameVersions:   version.GameVersions,
+		ReleaseChannel: version.Meta.ReleaseChannel,
+		Loaders:        version.Meta.Loaders,
+		Featured:       project.Featured,
+		Status:         project.Status,
+		ProjectId:      project.Id,
 		FileParts:      []string{"main_file"},
+		PrimaryFile:    "main_file",
+	}
+	for _, i := range version.Files {
+		data.FileParts = append(data.FileParts, i.Kind)
+	}
+	if version.Name != "" {
+		data.Name = version.Name
+	}
+	if version.Changelog != "" {
+		data.VersionBody = &version.Changelog
+	}
+	if version.Featured != nil {
+		data.Featured = *version.Featured
+	}
+	if version.Status != "" {
+		data.Status = version.Status
 	}
+	data.RequestedStatus = data.Status
 
-	field, err := mpw.CreateFormField("data")
-	if err != nil {
-		return "", err
+	scheduledAt := version.ScheduledAt
+	if scheduledAt.IsZero() && project.ScheduleDelay > 0 {
+		scheduledAt = time.Now().Add(project.ScheduleDelay)
+	}
+	if scheduledAt.IsZero() {
+		return modrinthPlanStructure{modrinthUploadDataStructure: data}
 	}
-	encoder := json.NewEncoder(field)
-	if err = encoder.Encode(data); err != nil {
-		return "", err
+
+	// scheduled versions stay as drafts until the release time
+	schedule := &modrinthScheduleStructure{Time: scheduledAt.UTC(), RequestedStatus: data.Status}
+	if schedule.RequestedStatus == "" {
+		schedule.RequestedStatus = "listed"
 	}
+	data.Status = "draft"
+	data.RequestedStatus = "draft"
+	return modrinthPlanStructure{data, schedule}
+}
+
+func (m *modrinth) ValidateVersion(ctx context.Context, project Project, version Version) (Plan, error) {
+	return Plan{
+		Data:     modrinthVersionData(project, version),
+		Warnings: unmappedDependencies(project, version.Meta.Dependencies),
+	}, nil
+}
 
-	file, err := mpw.CreateFormFile("main_file", filename)
+// schedule releases a draft version at the scheduled time
+func (m *modrinth) schedule(ctx context.Context, versionId string, schedule *modrinthScheduleStructure) error {
+	bodyBuf := new(bytes.Buffer)
+	if err := json.NewEncoder(bodyBuf).Encode(schedule); err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/version/%s/schedule", m.conf.Endpoint, url.PathEscape(versionId)), bodyBuf)
 	if err != nil {
-		return "", err
+		return err
 	}
-	_, _ = io.Copy(file, fileBody)
-	_ = mpw.Close()
+	req.Header.Set("User-Agent", m.conf.UserAgent)
+	req.Header.Set("Authorization", m.conf.Token)
+	req.Header.Set("Content-Type", "application/json")
 
-	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/version", m.conf.Endpoint), bodyBuf)
+	resp, err := m.client.Do(req)
 	if err != nil {
-		return "", err
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		var errData modrinthUploadDataError
+		if err := json.NewDecoder(resp.Body).Decode(&errData); err != nil {
+			return fmt.Errorf("modrinth remote error: %s", resp.Status)
+		}
+		return fmt.Errorf("modrinth remote error: %s -- %s", errData.Error, errData.Description)
+	}
+	return nil
+}
+
+func (m *modrinth) UploadVersion(ctx context.Context, project Project, version Version) (Result, error) {
+	plan := modrinthVersionData(project, version)
+	data := plan.modrinthUploadDataStructure
+
+	req, err := newMultipartRequest(ctx, http.MethodPost, fmt.Sprintf("%s/version", m.conf.Endpoint), func(mpw *multipart.Writer) error {
+		field, err := mpw.CreateFormField("data")
+		if err != nil {
+			return err
+		}
+		if err := json.NewEncoder(field).Encode(data); err != nil {
+			return err
+		}
+		file, err := mpw.CreateFormFile("main_file", version.Filename)
+		if err != nil {
+			return err
+		}
+		if _, err = io.Copy(file, version.Reader()); err != nil {
+			return err
+		}
+		for _, i := range version.Files {
+			file, err := mpw.CreateFormFile(i.Kind, i.Filename)
+			if err != nil {
+				return err
+			}
+			if _, err = io.Copy(file, i.Reader()); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return Result{}, err
 	}
 	req.Header.Set("User-Agent", m.conf.UserAgent)
 	req.Header.Set("Authorization", m.conf.Token)
-	req.Header.Add("Content-Type", mpw.FormDataContentType())
 
 	do, err := m.client.Do(req)
 	if err != nil {
-		return "", err
+		return Result{}, err
 	}
 	defer func(Body io.ReadCloser) {
 		_ = Body.Close()
@@ -101,16 +221,28 @@ func (m *modrinth) UploadVersion(projectId string, meta jar_parser.ModMetadata,
 		decoder := json.NewDecoder(do.Body)
 		err := decoder.Decode(&errData)
 		if err != nil {
-			return "", err
+			return Result{}, err
 		}
-		return "", fmt.Errorf("modrinth remote error: %s -- %s", errData.Error, errData.Description)
+		return Result{}, fmt.Errorf("modrinth remote error: %s -- %s", errData.Error, errData.Description)
 	}
 	var idData struct {
 		Id string `json:"id"`
 	}
 	err = json.NewDecoder(do.Body).Decode(&idData)
 	if err != nil {
-		return "", err
+		return Result{}, err
+	}
+	res := Result{
+		Id:       idData.Id,
+		Url:      versionUrl(project, "version", idData.Id),
+		Warnings: unmappedDependencies(project, version.Meta.Dependencies),
+	}
+
+	// the version exists now so a failed schedule must not fail the upload
+	if plan.Schedule != nil {
+		if err := m.schedule(ctx, idData.Id, plan.Schedule); err != nil {
+			res.Warnings = append(res.Warnings, fmt.Sprintf("failed to schedule release, the version is a draft: %s", err))
+		}
 	}
-	return idData.Id, nil
+	return res, nil
 }
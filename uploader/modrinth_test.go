@@ -2,12 +2,14 @@ package uploader
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	jar_parser "github.com/mrmelon54/mc-upload-api/jar-parser"
 	"github.com/mrmelon54/mc-upload-api/uploader/test"
 	"github.com/stretchr/testify/assert"
 	"net/http"
 	"testing"
+	"time"
 )
 
 func TestModrinth_UploadVersion(t *testing.T) {
@@ -21,23 +23,36 @@ func TestModrinth_UploadVersion(t *testing.T) {
 		assert.Equal(t, "data", dataPart.FormName())
 
 		var jData struct {
-			Name          string   `json:"name"`
-			VersionNumber string   `json:"version_number"`
-			Dependencies  []any    `json:"dependencies"`
-			GameVersions  []string `json:"game_versions"`
-			VersionType   string   `json:"version_type"`
-			Loaders       []string `json:"loaders"`
-			Featured      bool     `json:"featured"`
-			ProjectId     string   `json:"project_id"`
-			FileParts     []string `json:"file_parts"`
+			Name          string  `json:"name"`
+			VersionBody   *string `json:"version_body"`
+			VersionNumber string  `json:"version_number"`
+			Dependencies  []struct {
+				ProjectId      string `json:"project_id"`
+				DependencyType string `json:"dependency_type"`
+			} `json:"dependencies"`
+			GameVersions []string `json:"game_versions"`
+			VersionType  string   `json:"version_type"`
+			Loaders      []string `json:"loaders"`
+			Featured     bool     `json:"featured"`
+			ProjectId    string   `json:"project_id"`
+			FileParts    []string `json:"file_parts"`
 		}
 
 		assert.NoError(t, json.NewDecoder(dataPart).Decode(&jData))
+		assert.Equal(t, "Test Mod 1.0.0", jData.Name)
+		assert.Equal(t, "- Fixed a bug", *jData.VersionBody)
+		assert.Len(t, jData.Dependencies, 2)
+		assert.Equal(t, "P7dR8mSH", jData.Dependencies[0].ProjectId)
+		assert.Equal(t, "required", jData.Dependencies[0].DependencyType)
+		assert.Equal(t, "lhGA9TYQ", jData.Dependencies[1].ProjectId)
+		assert.Equal(t, "optional", jData.Dependencies[1].DependencyType)
+		assert.Equal(t, []string{"main_file", "sources"}, jData.FileParts)
 
 		for _, filePartName := range jData.FileParts {
 			filePart, err := mpr.NextPart()
 			assert.NoError(t, err)
 			assert.Equal(t, filePartName, filePart.FormName())
+			assert.NotEmpty(t, filePart.FileName())
 		}
 
 		rw.WriteHeader(http.StatusOK)
@@ -47,14 +62,122 @@ func TestModrinth_UploadVersion(t *testing.T) {
 
 	m := &modrinth{
 		conf:   ModrinthConfig{Token: "abcd1234"},
-		client: srv,
+		client: newPlatformClient(srv, ClientConfig{}),
 	}
-	mrId, err := m.UploadVersion("123", jar_parser.ModMetadata{
-		VersionNumber:  "1.0.0",
-		ReleaseChannel: "alpha",
-		GameVersions:   nil,
-		Loaders:        []string{"fabric", "forge"},
-	}, []string{"1.20", "1.20.1"}, "my-test-file.jar", bytes.NewReader([]byte{0x54, 0x54}))
+	mrId, err := m.UploadVersion(context.Background(), Project{
+		Id:  "123",
+		Url: "https://modrinth.com/mod/example",
+		Dependencies: map[string]string{
+			"fabric":       "P7dR8mSH",
+			"architectury": "lhGA9TYQ",
+		},
+	}, Version{
+		Meta: jar_parser.ModMetadata{
+			VersionNumber:  "1.0.0",
+			ReleaseChannel: "alpha",
+			GameVersions:   nil,
+			Loaders:        []string{"fabric", "forge"},
+			Dependencies: []jar_parser.Dependency{
+				{ModId: "fabric", Type: jar_parser.DependencyRequired},
+				{ModId: "architectury", Type: jar_parser.DependencyOptional},
+				{ModId: "unknown-mod", Type: jar_parser.DependencyRequired},
+			},
+		},
+		GameVersions: []string{"1.20", "1.20.1"},
+		Name:         "Test Mod 1.0.0",
+		Changelog:    "- Fixed a bug",
+		Filename:     "my-test-file.jar",
+		File:         bytes.NewReader([]byte{0x54, 0x54}),
+		Size:         2,
+		Files: []File{
+			{Kind: "sources", Filename: "my-test-file-sources.jar", File: bytes.NewReader([]byte{0x55}), Size: 1},
+		},
+	})
 	assert.NoError(t, err)
-	println("mrId:", mrId)
+	assert.Equal(t, "123aaa", mrId.Id)
+	assert.Equal(t, "https://modrinth.com/mod/example/version/123aaa", mrId.Url)
+	assert.Equal(t, []string{"required dependency unknown-mod has no project mapping"}, mrId.Warnings)
+}
+
+func TestModrinth_UploadVersion_Scheduled(t *testing.T) {
+	scheduledAt := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	r := http.NewServeMux()
+	r.HandleFunc("/version", func(rw http.ResponseWriter, req *http.Request) {
+		mpr, err := req.MultipartReader()
+		assert.NoError(t, err)
+		dataPart, err := mpr.NextPart()
+		assert.NoError(t, err)
+
+		var jData struct {
+			Featured        bool   `json:"featured"`
+			Status          string `json:"status"`
+			RequestedStatus string `json:"requested_status"`
+		}
+		assert.NoError(t, json.NewDecoder(dataPart).Decode(&jData))
+		assert.True(t, jData.Featured)
+		assert.Equal(t, "draft", jData.Status)
+		assert.Equal(t, "draft", jData.RequestedStatus)
+
+		rw.WriteHeader(http.StatusOK)
+		rw.Write([]byte(`{"id":"123aaa"}`))
+	})
+	scheduled := false
+	r.HandleFunc("/version/123aaa/schedule", func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodPost, req.Method)
+		var jData struct {
+			Time            time.Time `json:"time"`
+			RequestedStatus string    `json:"requested_status"`
+		}
+		assert.NoError(t, json.NewDecoder(req.Body).Decode(&jData))
+		assert.True(t, scheduledAt.Equal(jData.Time))
+		assert.Equal(t, "unlisted", jData.RequestedStatus)
+		scheduled = true
+		rw.WriteHeader(http.StatusNoContent)
+	})
+	srv := test.NewTestServer(r)
+
+	m := &modrinth{
+		conf:   ModrinthConfig{Token: "abcd1234"},
+		client: newPlatformClient(srv, ClientConfig{}),
+	}
+	featured := true
+	res, err := m.UploadVersion(context.Background(), Project{
+		Id:     "123",
+		Url:    "https://modrinth.com/mod/example",
+		Status: "listed",
+	}, Version{
+		Meta:         jar_parser.ModMetadata{VersionNumber: "1.0.0", ReleaseChannel: "release", Loaders: []string{"fabric"}},
+		GameVersions: []string{"1.20.1"},
+		Featured:     &featured,
+		Status:       "unlisted",
+		ScheduledAt:  scheduledAt,
+		Filename:     "my-test-file.jar",
+		File:         bytes.NewReader([]byte{0x54, 0x54}),
+		Size:         2,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "123aaa", res.Id)
+	assert.Empty(t, res.Warnings)
+	assert.True(t, scheduled)
+}
+
+func TestModrinthVersionData(t *testing.T) {
+	version := Version{Filename: "a.jar"}
+
+	plan := modrinthVersionData(Project{Featured: true, Status: "archived"}, version)
+	assert.True(t, plan.Featured)
+	assert.Equal(t, "archived", plan.Status)
+	assert.Equal(t, "archived", plan.RequestedStatus)
+	assert.Nil(t, plan.Schedule)
+
+	featured := false
+	version.Featured = &featured
+	plan = modrinthVersionData(Project{Featured: true, ScheduleDelay: time.Hour}, version)
+	assert.False(t, plan.Featured)
+	assert.Equal(t, "draft", plan.Status)
+	if assert.NotNil(t, plan.Schedule) {
+		assert.Equal(t, "listed", plan.Schedule.RequestedStatus)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), plan.Schedule.Time, time.Minute)
+	}
 }
@@ -0,0 +1,69 @@
+package uploader
+
+import (
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+)
+
+// multipartBody streams a multipart form through a pipe, the form is written
+// again for every request attempt
+type multipartBody struct {
+	boundary string
+	write    func(mpw *multipart.Writer) error
+}
+
+func (b multipartBody) writeTo(w io.Writer) error {
+	mpw := multipart.NewWriter(w)
+	if err := mpw.SetBoundary(b.boundary); err != nil {
+		return err
+	}
+	if err := b.write(mpw); err != nil {
+		return err
+	}
+	return mpw.Close()
+}
+
+func (b multipartBody) open() (io.ReadCloser, error) {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(b.writeTo(pw))
+	}()
+	return pr, nil
+}
+
+type countWriter int64
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	*c += countWriter(len(p))
+	return len(p), nil
+}
+
+// newMultipartRequest creates a request with a streamed multipart body, the form
+// is written once up front to find the content length as not all platforms
+// accept chunked uploads
+func newMultipartRequest(ctx context.Context, method, url string, write func(mpw *multipart.Writer) error) (*http.Request, error) {
+	body := multipartBody{
+		boundary: multipart.NewWriter(io.Discard).Boundary(),
+		write:    write,
+	}
+	var length countWriter
+	if err := body.writeTo(&length); err != nil {
+		return nil, err
+	}
+
+	rc, err := body.open()
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, rc)
+	if err != nil {
+		_ = rc.Close()
+		return nil, err
+	}
+	req.ContentLength = int64(length)
+	req.GetBody = body.open
+	req.Header.Set("Content-Type", "multipart/form-data; boundary="+body.boundary)
+	return req, nil
+}
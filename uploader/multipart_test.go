@@ -0,0 +1,52 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"testing"
+)
+
+func TestNewMultipartRequest(t *testing.T) {
+	version := Version{Filename: "test.jar", File: bytes.NewReader([]byte{0x54, 0x54, 0x54}), Size: 3}
+	req, err := newMultipartRequest(context.Background(), http.MethodPost, "http://localhost/upload", func(mpw *multipart.Writer) error {
+		if err := mpw.WriteField("data", "{}"); err != nil {
+			return err
+		}
+		file, err := mpw.CreateFormFile("file", version.Filename)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(file, version.Reader())
+		return err
+	})
+	assert.NoError(t, err)
+
+	first, err := io.ReadAll(req.Body)
+	assert.NoError(t, err)
+	assert.EqualValues(t, len(first), req.ContentLength)
+
+	// retries write the same body again
+	body, err := req.GetBody()
+	assert.NoError(t, err)
+	second, err := io.ReadAll(body)
+	assert.NoError(t, err)
+	assert.Equal(t, first, second)
+
+	_, params, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	assert.NoError(t, err)
+	mpr := multipart.NewReader(bytes.NewReader(first), params["boundary"])
+	part, err := mpr.NextPart()
+	assert.NoError(t, err)
+	assert.Equal(t, "data", part.FormName())
+	part, err = mpr.NextPart()
+	assert.NoError(t, err)
+	assert.Equal(t, "test.jar", part.FileName())
+	fileBytes, err := io.ReadAll(part)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x54, 0x54, 0x54}, fileBytes)
+}
@@ -0,0 +1,56 @@
+package uploader
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"net/http"
+)
+
+// Registry holds the configured uploaders by platform name
+type Registry map[string]Uploader
+
+type platformFactory func(node *yaml.Node, client *http.Client) (Uploader, error)
+
+func platformType[T any](newUploader func(T, *http.Client) Uploader) platformFactory {
+	return func(node *yaml.Node, client *http.Client) (Uploader, error) {
+		var conf T
+		if err := node.Decode(&conf); err != nil {
+			return nil, err
+		}
+		return newUploader(conf, client), nil
+	}
+}
+
+var platformTypes = map[string]platformFactory{
+	"modrinth":   platformType(NewModrinthUploader),
+	"curseforge": platformType(NewCurseforgeUploader),
+	"github":     platformType(NewGithubUploader),
+	"hangar":     platformType(NewHangarUploader),
+}
+
+// NewRegistry creates an uploader for each configured platform, the platform type
+// is read from the `type` key and defaults to the platform name
+func NewRegistry(platforms map[string]yaml.Node, client *http.Client) (Registry, error) {
+	r := make(Registry, len(platforms))
+	for name, node := range platforms {
+		var platform struct {
+			Type string `yaml:"type"`
+		}
+		if err := node.Decode(&platform); err != nil {
+			return nil, fmt.Errorf("platform %s: %w", name, err)
+		}
+		if platform.Type == "" {
+			platform.Type = name
+		}
+		factory, ok := platformTypes[platform.Type]
+		if !ok {
+			return nil, fmt.Errorf("platform %s: unknown type: %s", name, platform.Type)
+		}
+		upld, err := factory(&node, client)
+		if err != nil {
+			return nil, fmt.Errorf("platform %s: %w", name, err)
+		}
+		r[name] = upld
+	}
+	return r, nil
+}
@@ -0,0 +1,49 @@
+package uploader
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+	"net/http"
+	"testing"
+)
+
+const testPlatformsYml = `
+modrinth:
+  endpoint: https://api.modrinth.com/v2
+  token: abcd1234
+modrinth-staging:
+  type: modrinth
+  endpoint: https://staging-api.modrinth.com/v2
+  token: abcd1234
+curseforge:
+  endpoint: https://minecraft.curseforge.com/api
+  token: abcd1234
+`
+
+func TestNewRegistry(t *testing.T) {
+	var platforms map[string]yaml.Node
+	assert.NoError(t, yaml.Unmarshal([]byte(testPlatformsYml), &platforms))
+
+	r, err := NewRegistry(platforms, http.DefaultClient)
+	assert.NoError(t, err)
+	assert.Len(t, r, 3)
+	assert.IsType(t, &modrinth{}, r["modrinth"])
+	assert.IsType(t, &modrinth{}, r["modrinth-staging"])
+	assert.Equal(t, "https://staging-api.modrinth.com/v2", r["modrinth-staging"].(*modrinth).conf.Endpoint)
+	assert.IsType(t, &curseforge{}, r["curseforge"])
+
+	var unknownPlatforms map[string]yaml.Node
+	assert.NoError(t, yaml.Unmarshal([]byte("example:\n  token: abcd1234\n"), &unknownPlatforms))
+	_, err = NewRegistry(unknownPlatforms, http.DefaultClient)
+	assert.EqualError(t, err, "platform example: unknown type: example")
+
+	var emptyPlatforms map[string]yaml.Node
+	assert.NoError(t, yaml.Unmarshal([]byte("github: {}\n"), &emptyPlatforms))
+	r, err = NewRegistry(emptyPlatforms, http.DefaultClient)
+	assert.NoError(t, err)
+	_, err = r["github"].UploadVersion(context.Background(), Project{}, Version{})
+	assert.ErrorIs(t, err, ErrNotConfigured)
+	_, err = r["github"].ValidateVersion(context.Background(), Project{}, Version{})
+	assert.ErrorIs(t, err, ErrNotConfigured)
+}
@@ -1,10 +1,134 @@
 package uploader
 
 import (
+	"context"
+	"fmt"
 	jar_parser "github.com/mrmelon54/mc-upload-api/jar-parser"
 	"io"
+	"net/url"
+	"time"
 )
 
 type Uploader interface {
-	UploadVersion(projectId string, meta jar_parser.ModMetadata, versions []string, filename string, fileBody io.Reader) (string, error)
+	UploadVersion(ctx context.Context, project Project, version Version) (Result, error)
+
+	// ValidateVersion reports what would be published without uploading anything
+	ValidateVersion(ctx context.Context, project Project, version Version) (Plan, error)
+}
+
+// Plan describes the version an uploader would publish
+type Plan struct {
+	// Data is the version metadata which would be sent to the platform
+	Data any
+
+	Warnings []string
+}
+
+// Result describes the version created on a platform
+type Result struct {
+	Id string
+
+	// Url links to the version page, empty if the project page is unknown
+	Url string
+
+	// Warnings list anything which was left out of the upload
+	Warnings []string
+}
+
+// Project contains the platform specific settings for a single project
+type Project struct {
+	Id string
+
+	// Url is the project page on the platform
+	Url string
+
+	// Dependencies maps mod IDs declared in the jar to project IDs or slugs on the platform
+	Dependencies map[string]string
+
+	// Channels maps release channels to named channels on the platform
+	Channels map[string]string
+
+	// PlatformVersions lists the supported versions of platforms which are not
+	// versioned like the game
+	PlatformVersions map[string][]string
+
+	// Featured, Status and ScheduleDelay are the release defaults, used by Modrinth
+	Featured      bool
+	Status        string
+	ScheduleDelay time.Duration
+}
+
+// Version contains the build being published to a platform
+type Version struct {
+	Meta         jar_parser.ModMetadata
+	GameVersions []string
+
+	// Name is the display name, platforms use their own default when empty
+	Name string
+
+	// Changelog is formatted as markdown
+	Changelog string
+
+	// GitCommit is the commit the version was built from, empty when unknown
+	GitCommit string
+
+	// Featured, Status and ScheduledAt replace the project defaults when set
+	Featured    *bool
+	Status      string
+	ScheduledAt time.Time
+
+	Filename string
+
+	// File is read from the start for every request so uploads can be retried
+	File io.ReaderAt
+	Size int64
+
+	// Files are the additional files published alongside the main file
+	Files []File
+}
+
+// Reader returns a new reader over the whole file
+func (v Version) Reader() io.Reader {
+	return io.NewSectionReader(v.File, 0, v.Size)
+}
+
+// File is an additional file of a version, like the sources or javadoc jar
+type File struct {
+	// Kind is sources, javadoc or dev
+	Kind     string
+	Filename string
+	File     io.ReaderAt
+	Size     int64
+}
+
+// Reader returns a new reader over the whole file
+func (f File) Reader() io.Reader {
+	return io.NewSectionReader(f.File, 0, f.Size)
+}
+
+// versionUrl joins elem onto the project page
+func versionUrl(project Project, elem ...string) string {
+	if project.Url == "" {
+		return ""
+	}
+	u, err := url.JoinPath(project.Url, elem...)
+	if err != nil {
+		return ""
+	}
+	return u
+}
+
+// unmappedDependencies warns about required dependencies which have no project
+// on the platform
+func unmappedDependencies(project Project, deps []jar_parser.Dependency) []string {
+	var warnings []string
+	for _, i := range deps {
+		if i.Type != jar_parser.DependencyRequired {
+			continue
+		}
+		if _, ok := project.Dependencies[i.ModId]; !ok {
+			warnings = append(warnings, fmt.Sprintf("required dependency %s has no project mapping", i.ModId))
+		}
+	}
+	return warnings
 }